@@ -2,8 +2,11 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/mitchellh/go-homedir"
 	msdf "github.com/moozd/msdf/pkg"
@@ -55,12 +58,88 @@ func init() {
 				os.Exit(1)
 			}
 
+			ppem, err := cmd.Flags().GetFloat64("ppem")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			pxRange, err := cmd.Flags().GetFloat64("range")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			width, err := cmd.Flags().GetInt("width")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			height, err := cmd.Flags().GetInt("height")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			translate, err := getTranslate(cmd)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
 			debug, err := cmd.Flags().GetBool("debug")
 			if err != nil {
 				fmt.Println(err)
 				os.Exit(1)
 			}
 
+			mode, err := getMode(cmd)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			correction, err := getErrorCorrection(cmd)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			coloring, err := getColoring(cmd)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			angle, err := getCornerAngle(cmd)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fillRule, err := getFillRule(cmd)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			workers, err := cmd.Flags().GetInt("workers")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			keepOrientation, err := cmd.Flags().GetBool("keep-orientation")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			if c == "" {
+				fmt.Println("no character given, use --char")
+				os.Exit(1)
+			}
 			char := []rune(c)[0]
 			fontFile, err := homedir.Expand(addr)
 			if err != nil {
@@ -78,16 +157,43 @@ func init() {
 				debugPath = outDir
 			}
 			cfg := &msdf.Config{
-				Seed:  seed,
-				Scale: scale,
-				Debug: debugPath,
+				Seed:            seed,
+				Width:           width,
+				Height:          height,
+				PixelsPerEm:     ppem,
+				DistanceRange:   pxRange,
+				Translate:       translate,
+				Scale:           scale,
+				Debug:           debugPath,
+				Mode:            mode,
+				ErrorCorrection: correction,
+				Coloring:        coloring,
+				CornerAngle:     angle,
+				FillRule:        fillRule,
+				KeepOrientation: keepOrientation,
+				Workers:         workers,
+			}
+			msdfgen, err := msdf.New(fontFile, cfg)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
 			}
-			msdfgen, _ := msdf.New(fontFile, cfg)
-			s := msdfgen.Get(char)
 
-			s.Save(filepath.Join(outDir, fmt.Sprintf("%c.png", char)))
+			s, err := msdfgen.Get(char)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			if err := s.Save(filepath.Join(outDir, fmt.Sprintf("%c.png", char))); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
 			if debug {
-				msdfgen.Debug(char, s)
+				if err := msdfgen.Debug(char, s); err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
 			}
 
 		},
@@ -97,9 +203,374 @@ func init() {
 	glyphCmd.Flags().StringP("char", "c", "", "Character.")
 	glyphCmd.Flags().StringP("out", "o", ".", "Output dir path.")
 	glyphCmd.Flags().Uint("seed", 0, "coloring seed")
-	glyphCmd.Flags().Float64("scale", 1.0, "texture scale")
+	glyphCmd.Flags().Int("width", 0, "texture width in pixels, 0 fits the glyph")
+	glyphCmd.Flags().Int("height", 0, "texture height in pixels, 0 fits the glyph")
+	glyphCmd.Flags().Float64("ppem", 32, "font size in texture pixels per em")
+	glyphCmd.Flags().Float64("range", 2, "distance range in texture pixels")
+	glyphCmd.Flags().String("translate", "0,0", "outline offset in em units as x,y, used with --width and --height")
+	glyphCmd.Flags().Float64("scale", 1.0, "multiplies the pixels per em")
+	glyphCmd.Flags().String("mode", "msdf", "field type: msdf, sdf, psdf or mtsdf")
+	glyphCmd.Flags().String("error-correction", "edge-priority", "clash correction: off, edge-priority or full")
+	glyphCmd.Flags().String("coloring", "simple", "edge coloring strategy: simple, inktrap or distance")
+	glyphCmd.Flags().String("angle", "3", "corner angle threshold in radians, or degrees with a d suffix (e.g. 170d)")
+	glyphCmd.Flags().String("fill-rule", "nonzero", "inside test fixing the distance sign: nonzero, evenodd or off")
+	glyphCmd.Flags().Bool("keep-orientation", false, "keep contours in the direction the font draws them")
+	glyphCmd.Flags().Int("workers", 0, "goroutines rendering a glyph, 0 uses every CPU")
 
 	rootCmd.AddCommand(glyphCmd)
+
+	var atlasCmd = &cobra.Command{
+		Use:   "atlas",
+		Short: "Create a msdf atlas",
+		Long:  "It will generate msdf atlas pages and a metadata file for a set of characters",
+		Run: func(cmd *cobra.Command, args []string) {
+			addr, err := cmd.Flags().GetString("font")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			output, err := cmd.Flags().GetString("out")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			chars, err := cmd.Flags().GetString("chars")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			name, err := cmd.Flags().GetString("name")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			seed, err := cmd.Flags().GetUint("seed")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			scale, err := cmd.Flags().GetFloat64("scale")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			ppem, err := cmd.Flags().GetFloat64("ppem")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			pxRange, err := cmd.Flags().GetFloat64("range")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			padding, err := cmd.Flags().GetInt("padding")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			maxWidth, err := cmd.Flags().GetInt("max-width")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			maxHeight, err := cmd.Flags().GetInt("max-height")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			pot, err := cmd.Flags().GetBool("pot")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			square, err := cmd.Flags().GetBool("square")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			mode, err := getMode(cmd)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			correction, err := getErrorCorrection(cmd)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			coloring, err := getColoring(cmd)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			angle, err := getCornerAngle(cmd)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fillRule, err := getFillRule(cmd)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			workers, err := cmd.Flags().GetInt("workers")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			keepOrientation, err := cmd.Flags().GetBool("keep-orientation")
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fontFile, err := homedir.Expand(addr)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			outDir, err := homedir.Expand(output)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			cfg := &msdf.Config{
+				Seed:            seed,
+				PixelsPerEm:     ppem,
+				DistanceRange:   pxRange,
+				Scale:           scale,
+				Mode:            mode,
+				ErrorCorrection: correction,
+				Coloring:        coloring,
+				CornerAngle:     angle,
+				FillRule:        fillRule,
+				KeepOrientation: keepOrientation,
+				Workers:         workers,
+			}
+			msdfgen, err := msdf.New(fontFile, cfg)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			atlas, err := msdfgen.Atlas([]rune(chars), &msdf.AtlasConfig{
+				Padding:    padding,
+				MaxWidth:   maxWidth,
+				MaxHeight:  maxHeight,
+				PowerOfTwo: pot,
+				Square:     square,
+			})
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			for _, r := range chars {
+				if atlas.Glyph(r) == nil {
+					fmt.Printf("skipping %q: the font has no glyph for it\n", r)
+				}
+			}
+
+			var pages []string
+			for i, page := range atlas.Pages {
+				file := fmt.Sprintf("%s_%d.png", name, i)
+				if len(atlas.Pages) == 1 {
+					file = fmt.Sprintf("%s.png", name)
+				}
+				if err := page.Save(filepath.Join(outDir, file)); err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+				pages = append(pages, file)
+			}
+
+			switch format {
+			case "json":
+				err = atlas.SaveJSON(filepath.Join(outDir, name+".json"))
+			case "txt":
+				err = atlas.SaveBMFont(filepath.Join(outDir, name+".fnt"), msdf.BMFontText, pages)
+			case "xml":
+				err = atlas.SaveBMFont(filepath.Join(outDir, name+".fnt"), msdf.BMFontXML, pages)
+			case "bin":
+				err = atlas.SaveBMFont(filepath.Join(outDir, name+".fnt"), msdf.BMFontBinary, pages)
+			default:
+				err = fmt.Errorf("unknown format %q, use one of json, txt, xml, bin", format)
+			}
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		},
+	}
+	atlasCmd.Flags().StringP("font", "f", "", "Font path.")
+	atlasCmd.Flags().StringP("chars", "c", "", "Characters to include.")
+	atlasCmd.Flags().StringP("out", "o", ".", "Output dir path.")
+	atlasCmd.Flags().StringP("name", "n", "atlas", "Output file name without extension.")
+	atlasCmd.Flags().String("format", "json", "Metadata format: json, txt, xml or bin (BMFont).")
+	atlasCmd.Flags().Uint("seed", 0, "coloring seed")
+	atlasCmd.Flags().Float64("ppem", 32, "font size in texture pixels per em")
+	atlasCmd.Flags().Float64("range", 2, "distance range in texture pixels")
+	atlasCmd.Flags().Float64("scale", 1.0, "multiplies the pixels per em")
+	atlasCmd.Flags().String("mode", "msdf", "field type: msdf, sdf, psdf or mtsdf")
+	atlasCmd.Flags().String("error-correction", "edge-priority", "clash correction: off, edge-priority or full")
+	atlasCmd.Flags().String("coloring", "simple", "edge coloring strategy: simple, inktrap or distance")
+	atlasCmd.Flags().String("angle", "3", "corner angle threshold in radians, or degrees with a d suffix (e.g. 170d)")
+	atlasCmd.Flags().String("fill-rule", "nonzero", "inside test fixing the distance sign: nonzero, evenodd or off")
+	atlasCmd.Flags().Bool("keep-orientation", false, "keep contours in the direction the font draws them")
+	atlasCmd.Flags().Int("workers", 0, "goroutines rendering a glyph, 0 uses every CPU")
+	atlasCmd.Flags().Int("padding", 2, "empty pixels around each glyph")
+	atlasCmd.Flags().Int("max-width", 1024, "maximum page width")
+	atlasCmd.Flags().Int("max-height", 1024, "maximum page height")
+	atlasCmd.Flags().Bool("pot", false, "use power of two page sizes")
+	atlasCmd.Flags().Bool("square", false, "use square pages")
+
+	rootCmd.AddCommand(atlasCmd)
+}
+
+func getMode(cmd *cobra.Command) (msdf.Mode, error) {
+	mode, err := cmd.Flags().GetString("mode")
+	if err != nil {
+		return msdf.MSDF, err
+	}
+
+	switch mode {
+	case "msdf":
+		return msdf.MSDF, nil
+	case "sdf":
+		return msdf.SDF, nil
+	case "psdf":
+		return msdf.PSDF, nil
+	case "mtsdf":
+		return msdf.MTSDF, nil
+	}
+
+	return msdf.MSDF, fmt.Errorf("unknown mode %q, use one of msdf, sdf, psdf, mtsdf", mode)
+}
+
+func getErrorCorrection(cmd *cobra.Command) (msdf.ErrorCorrection, error) {
+	correction, err := cmd.Flags().GetString("error-correction")
+	if err != nil {
+		return msdf.ErrorCorrectionEdgePriority, err
+	}
+
+	switch correction {
+	case "off":
+		return msdf.ErrorCorrectionOff, nil
+	case "edge-priority":
+		return msdf.ErrorCorrectionEdgePriority, nil
+	case "full":
+		return msdf.ErrorCorrectionFull, nil
+	}
+
+	return msdf.ErrorCorrectionEdgePriority, fmt.Errorf("unknown error correction %q, use one of off, edge-priority, full", correction)
+}
+
+func getColoring(cmd *cobra.Command) (msdf.EdgeColorer, error) {
+	coloring, err := cmd.Flags().GetString("coloring")
+	if err != nil {
+		return nil, err
+	}
+
+	switch coloring {
+	case "simple":
+		return msdf.SimpleColorer{}, nil
+	case "inktrap":
+		return msdf.InkTrapColorer{}, nil
+	case "distance":
+		return msdf.DistanceColorer{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown coloring %q, use one of simple, inktrap, distance", coloring)
+}
+
+func getFillRule(cmd *cobra.Command) (msdf.FillRule, error) {
+	rule, err := cmd.Flags().GetString("fill-rule")
+	if err != nil {
+		return msdf.FillRuleNonZero, err
+	}
+
+	switch rule {
+	case "nonzero":
+		return msdf.FillRuleNonZero, nil
+	case "evenodd":
+		return msdf.FillRuleEvenOdd, nil
+	case "off":
+		return msdf.FillRuleOff, nil
+	}
+
+	return msdf.FillRuleNonZero, fmt.Errorf("unknown fill rule %q, use one of nonzero, evenodd, off", rule)
+}
+
+func getTranslate(cmd *cobra.Command) (msdf.Point, error) {
+	translate, err := cmd.Flags().GetString("translate")
+	if err != nil {
+		return msdf.Point{}, err
+	}
+
+	parts := strings.Split(translate, ",")
+	if len(parts) != 2 {
+		return msdf.Point{}, fmt.Errorf("invalid translate %q, use x,y", translate)
+	}
+
+	x, errX := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	y, errY := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if errX != nil || errY != nil {
+		return msdf.Point{}, fmt.Errorf("invalid translate %q, use x,y", translate)
+	}
+
+	return msdf.Point{X: x, Y: y}, nil
+}
+
+func getCornerAngle(cmd *cobra.Command) (float64, error) {
+	angle, err := cmd.Flags().GetString("angle")
+	if err != nil {
+		return 0, err
+	}
+
+	degrees := strings.HasSuffix(angle, "d")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(angle, "d"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid angle %q, use radians or degrees with a d suffix", angle)
+	}
+
+	limit := math.Pi
+	if degrees {
+		limit = 180
+	}
+	if v <= 0 || v > limit {
+		return 0, fmt.Errorf("invalid angle %q, it must be in (0, π] radians or (0, 180] degrees", angle)
+	}
+
+	if degrees {
+		v = v * math.Pi / 180
+	}
+	return v, nil
 }
 
 func main() {
@@ -0,0 +1,191 @@
+package msdf
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"sort"
+)
+
+type AtlasConfig struct {
+	// Padding is the number of empty pixels kept around each glyph.
+	Padding int
+	// MaxWidth and MaxHeight limit the size of a single page, default 1024.
+	MaxWidth, MaxHeight int
+	PowerOfTwo          bool
+	Square              bool
+}
+
+type AtlasGlyph struct {
+	Rune rune
+	Page int
+	// Rect is the glyph location inside its page, in pixels with the origin at the top left.
+	Rect image.Rectangle
+	// Metrics places the glyph in text, its AtlasBounds is Rect.
+	Metrics *GlyphMetrics
+}
+
+type Atlas struct {
+	Pages  []*Glyph
+	Glyphs []*AtlasGlyph
+	msdf   *Msdf
+	cfg    *AtlasConfig
+}
+
+// Atlas renders and packs the runes, runes the font has no glyph for are
+// skipped. A nil cfg uses the defaults.
+func (m *Msdf) Atlas(runes []rune, cfg *AtlasConfig) (*Atlas, error) {
+	if cfg == nil {
+		cfg = &AtlasConfig{}
+	}
+	pageW, pageH := cfg.pageSize()
+
+	var glyphs []*AtlasGlyph
+	var textures []*Glyph
+
+	seen := map[rune]bool{}
+	for _, r := range runes {
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+
+		g := &AtlasGlyph{Rune: r}
+
+		tex, err := m.Get(r)
+		if errors.Is(err, ErrGlyphNotFound) {
+			// like Kerning, runes the font lacks are left out of the atlas
+			continue
+		}
+		if errors.Is(err, ErrEmptyGlyph) {
+			// kept for its advance, there is nothing to pack
+			tex = nil
+			g.Metrics, err = m.GlyphMetrics(r)
+		} else if err == nil {
+			g.Metrics = tex.Metrics
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		glyphs = append(glyphs, g)
+		textures = append(textures, tex)
+	}
+
+	var order []int
+	for i, tex := range textures {
+		if tex != nil {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a := textures[order[i]].Image().Bounds()
+		b := textures[order[j]].Image().Bounds()
+		if a.Dy() != b.Dy() {
+			return a.Dy() > b.Dy()
+		}
+		return a.Dx() > b.Dx()
+	})
+
+	var bins []*maxRects
+	for _, i := range order {
+		size := textures[i].Image().Bounds().Size()
+		w := size.X + 2*cfg.Padding
+		h := size.Y + 2*cfg.Padding
+
+		if w > pageW || h > pageH {
+			return nil, fmt.Errorf("glyph %q (%dx%d) does not fit in a %dx%d page", glyphs[i].Rune, size.X, size.Y, pageW, pageH)
+		}
+
+		page := -1
+		var rect image.Rectangle
+		for p, bin := range bins {
+			if r, ok := bin.insert(w, h); ok {
+				page, rect = p, r
+				break
+			}
+		}
+
+		if page < 0 {
+			bin := newMaxRects(pageW, pageH)
+			rect, _ = bin.insert(w, h)
+			bins = append(bins, bin)
+			page = len(bins) - 1
+		}
+
+		glyphs[i].Page = page
+		glyphs[i].Rect = rect.Inset(cfg.Padding)
+		glyphs[i].Metrics.AtlasBounds = glyphs[i].Rect
+	}
+
+	// pages are cropped to the glyphs, all to the same size since BMFont
+	// readers divide by a single scaleW and scaleH
+	var extent image.Point
+	for _, i := range order {
+		g := glyphs[i]
+		extent.X = max(extent.X, g.Rect.Max.X+cfg.Padding)
+		extent.Y = max(extent.Y, g.Rect.Max.Y+cfg.Padding)
+	}
+
+	atlas := &Atlas{Glyphs: glyphs, msdf: m, cfg: cfg}
+	w, h := cfg.fit(extent.X, extent.Y)
+	for range bins {
+		atlas.Pages = append(atlas.Pages, newTexture(m.cfg.Mode, w, h))
+	}
+
+	for _, i := range order {
+		g := glyphs[i]
+		src := textures[i].Image()
+		dst := atlas.Pages[g.Page].Image()
+		draw.Draw(dst, g.Rect, src, src.Bounds().Min, draw.Src)
+	}
+
+	return atlas, nil
+}
+
+func (a *Atlas) Glyph(r rune) *AtlasGlyph {
+	for _, g := range a.Glyphs {
+		if g.Rune == r {
+			return g
+		}
+	}
+	return nil
+}
+
+func (cfg *AtlasConfig) pageSize() (int, int) {
+	w, h := cfg.MaxWidth, cfg.MaxHeight
+	if w <= 0 {
+		w = 1024
+	}
+	if h <= 0 {
+		h = 1024
+	}
+
+	if cfg.PowerOfTwo {
+		w, h = prevPowerOfTwo(w), prevPowerOfTwo(h)
+	}
+
+	if cfg.Square {
+		w = min(w, h)
+		h = w
+	}
+
+	return w, h
+}
+
+// fit grows the used area of a page so it satisfies the page constraints.
+func (cfg *AtlasConfig) fit(w, h int) (int, int) {
+	w, h = max(w, 1), max(h, 1)
+
+	if cfg.PowerOfTwo {
+		w, h = nextPowerOfTwo(w), nextPowerOfTwo(h)
+	}
+
+	if cfg.Square {
+		w = max(w, h)
+		h = w
+	}
+
+	return w, h
+}
@@ -0,0 +1,33 @@
+package msdf
+
+import "testing"
+
+func TestAtlasSkipsMissingGlyphs(t *testing.T) {
+	m := newTestMsdf(t, &Config{})
+
+	atlas, err := m.Atlas([]rune("A\U0010FFFD B"), &AtlasConfig{Padding: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g := atlas.Glyph('\U0010FFFD'); g != nil {
+		t.Errorf("missing rune has an atlas glyph %+v", g)
+	}
+	for _, r := range "A B" {
+		if atlas.Glyph(r) == nil {
+			t.Errorf("no atlas glyph for %q", r)
+		}
+	}
+}
+
+func TestAtlasNilConfig(t *testing.T) {
+	m := newTestMsdf(t, &Config{})
+
+	atlas, err := m.Atlas([]rune("AB"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(atlas.Pages) != 1 {
+		t.Errorf("%d pages, want 1", len(atlas.Pages))
+	}
+}
@@ -0,0 +1,344 @@
+package msdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+type BMFontFormat int
+
+const (
+	BMFontText BMFontFormat = iota
+	BMFontXML
+	BMFontBinary
+)
+
+// bmFont mirrors the blocks of an AngelCode BMFont descriptor, all values are in pixels.
+type bmFont struct {
+	XMLName  xml.Name   `xml:"font"`
+	Info     bmInfo     `xml:"info"`
+	Common   bmCommon   `xml:"common"`
+	Pages    []bmPage   `xml:"pages>page"`
+	Chars    bmChars    `xml:"chars"`
+	Kernings bmKernings `xml:"kernings"`
+}
+
+type bmInfo struct {
+	Face     string `xml:"face,attr"`
+	Size     int    `xml:"size,attr"`
+	Bold     int    `xml:"bold,attr"`
+	Italic   int    `xml:"italic,attr"`
+	Charset  string `xml:"charset,attr"`
+	Unicode  int    `xml:"unicode,attr"`
+	StretchH int    `xml:"stretchH,attr"`
+	Smooth   int    `xml:"smooth,attr"`
+	AA       int    `xml:"aa,attr"`
+	Padding  string `xml:"padding,attr"`
+	Spacing  string `xml:"spacing,attr"`
+	Outline  int    `xml:"outline,attr"`
+
+	spacing [2]int
+}
+
+type bmCommon struct {
+	LineHeight int `xml:"lineHeight,attr"`
+	Base       int `xml:"base,attr"`
+	ScaleW     int `xml:"scaleW,attr"`
+	ScaleH     int `xml:"scaleH,attr"`
+	Pages      int `xml:"pages,attr"`
+	Packed     int `xml:"packed,attr"`
+	AlphaChnl  int `xml:"alphaChnl,attr"`
+	RedChnl    int `xml:"redChnl,attr"`
+	GreenChnl  int `xml:"greenChnl,attr"`
+	BlueChnl   int `xml:"blueChnl,attr"`
+}
+
+type bmPage struct {
+	ID   int    `xml:"id,attr"`
+	File string `xml:"file,attr"`
+}
+
+type bmChars struct {
+	Count int      `xml:"count,attr"`
+	Chars []bmChar `xml:"char"`
+}
+
+type bmChar struct {
+	ID       rune `xml:"id,attr"`
+	X        int  `xml:"x,attr"`
+	Y        int  `xml:"y,attr"`
+	Width    int  `xml:"width,attr"`
+	Height   int  `xml:"height,attr"`
+	XOffset  int  `xml:"xoffset,attr"`
+	YOffset  int  `xml:"yoffset,attr"`
+	XAdvance int  `xml:"xadvance,attr"`
+	Page     int  `xml:"page,attr"`
+	Chnl     int  `xml:"chnl,attr"`
+}
+
+type bmKernings struct {
+	Count    int         `xml:"count,attr"`
+	Kernings []bmKerning `xml:"kerning"`
+}
+
+type bmKerning struct {
+	First  rune `xml:"first,attr"`
+	Second rune `xml:"second,attr"`
+	Amount int  `xml:"amount,attr"`
+}
+
+// SaveBMFont writes the atlas descriptor, pages lists the file name of every atlas page.
+func (a *Atlas) SaveBMFont(s string, format BMFontFormat, pages []string) error {
+	file, err := os.Create(s)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := a.WriteBMFont(file, format, pages); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func (a *Atlas) WriteBMFont(w io.Writer, format BMFontFormat, pages []string) error {
+	if len(pages) != len(a.Pages) {
+		return fmt.Errorf("bmfont export needs %d page file names, got %d", len(a.Pages), len(pages))
+	}
+
+	fnt, err := a.bmFont(pages)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case BMFontText:
+		return fnt.writeText(w)
+	case BMFontXML:
+		return fnt.writeXML(w)
+	case BMFontBinary:
+		return fnt.writeBinary(w)
+	}
+
+	return fmt.Errorf("unknown bmfont format %d", format)
+}
+
+func (a *Atlas) bmFont(pages []string) (*bmFont, error) {
+	l, err := a.layout()
+	if err != nil {
+		return nil, err
+	}
+
+	var buff sfnt.Buffer
+	face, err := a.msdf.font.Name(&buff, sfnt.NameIDFamily)
+	if err != nil {
+		face = ""
+	}
+
+	px := func(v float64) int {
+		return int(math.Round(v * l.size))
+	}
+
+	scaleW, scaleH := 0, 0
+	for _, p := range a.Pages {
+		b := p.Image().Bounds()
+		scaleW = max(scaleW, b.Dx())
+		scaleH = max(scaleH, b.Dy())
+	}
+
+	spacing := 2 * a.cfg.Padding
+
+	fnt := &bmFont{
+		Info: bmInfo{
+			Face:     face,
+			Size:     int(math.Round(l.size)),
+			Unicode:  1,
+			StretchH: 100,
+			Smooth:   1,
+			AA:       1,
+			Padding:  "0,0,0,0",
+			Spacing:  fmt.Sprintf("%d,%d", spacing, spacing),
+			spacing:  [2]int{spacing, spacing},
+		},
+		Common: bmCommon{
+			LineHeight: px(l.lineHeight),
+			Base:       px(l.ascender),
+			ScaleW:     scaleW,
+			ScaleH:     scaleH,
+			Pages:      len(pages),
+		},
+	}
+
+	for i, p := range pages {
+		fnt.Pages = append(fnt.Pages, bmPage{ID: i, File: p})
+	}
+
+	for _, gl := range l.glyphs {
+		c := bmChar{
+			ID:       gl.glyph.Rune,
+			XAdvance: px(gl.advance),
+			Page:     gl.glyph.Page,
+			Chnl:     15,
+		}
+
+		if gl.plane != nil {
+			rect := gl.glyph.Rect
+			c.X, c.Y = rect.Min.X, rect.Min.Y
+			c.Width, c.Height = rect.Dx(), rect.Dy()
+			c.XOffset = px(gl.plane.Left)
+			c.YOffset = px(l.ascender - gl.plane.Top)
+		}
+
+		fnt.Chars.Chars = append(fnt.Chars.Chars, c)
+	}
+	fnt.Chars.Count = len(fnt.Chars.Chars)
+
+	for _, k := range l.kerning {
+		// pairs rounding to no pixels are left out
+		if amount := px(k.Advance); amount != 0 {
+			fnt.Kernings.Kernings = append(fnt.Kernings.Kernings, bmKerning{First: k.First, Second: k.Second, Amount: amount})
+		}
+	}
+	fnt.Kernings.Count = len(fnt.Kernings.Kernings)
+
+	return fnt, nil
+}
+
+func (f *bmFont) writeText(w io.Writer) error {
+	var b bytes.Buffer
+
+	i := f.Info
+	fmt.Fprintf(&b, "info face=%q size=%d bold=%d italic=%d charset=%q unicode=%d stretchH=%d smooth=%d aa=%d padding=%s spacing=%s outline=%d\n",
+		i.Face, i.Size, i.Bold, i.Italic, i.Charset, i.Unicode, i.StretchH, i.Smooth, i.AA, i.Padding, i.Spacing, i.Outline)
+
+	c := f.Common
+	fmt.Fprintf(&b, "common lineHeight=%d base=%d scaleW=%d scaleH=%d pages=%d packed=%d alphaChnl=%d redChnl=%d greenChnl=%d blueChnl=%d\n",
+		c.LineHeight, c.Base, c.ScaleW, c.ScaleH, c.Pages, c.Packed, c.AlphaChnl, c.RedChnl, c.GreenChnl, c.BlueChnl)
+
+	for _, p := range f.Pages {
+		fmt.Fprintf(&b, "page id=%d file=%q\n", p.ID, p.File)
+	}
+
+	fmt.Fprintf(&b, "chars count=%d\n", f.Chars.Count)
+	for _, ch := range f.Chars.Chars {
+		fmt.Fprintf(&b, "char id=%d x=%d y=%d width=%d height=%d xoffset=%d yoffset=%d xadvance=%d page=%d chnl=%d\n",
+			ch.ID, ch.X, ch.Y, ch.Width, ch.Height, ch.XOffset, ch.YOffset, ch.XAdvance, ch.Page, ch.Chnl)
+	}
+
+	if f.Kernings.Count > 0 {
+		fmt.Fprintf(&b, "kernings count=%d\n", f.Kernings.Count)
+		for _, k := range f.Kernings.Kernings {
+			fmt.Fprintf(&b, "kerning first=%d second=%d amount=%d\n", k.First, k.Second, k.Amount)
+		}
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func (f *bmFont) writeXML(w io.Writer) error {
+	if _, err := io.WriteString(w, "<?xml version=\"1.0\"?>\n"); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(f); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeBinary writes the version 3 binary layout, see https://www.angelcode.com/products/bmfont/doc/file_format.html
+func (f *bmFont) writeBinary(w io.Writer) error {
+	var b bytes.Buffer
+	le := binary.LittleEndian
+
+	b.WriteString("BMF")
+	b.WriteByte(3)
+
+	block := func(kind byte, data []byte) {
+		b.WriteByte(kind)
+		b.Write(le.AppendUint32(nil, uint32(len(data))))
+		b.Write(data)
+	}
+
+	i := f.Info
+	var bits byte
+	if i.Smooth != 0 {
+		bits |= 1 << 7
+	}
+	if i.Unicode != 0 {
+		bits |= 1 << 6
+	}
+	if i.Italic != 0 {
+		bits |= 1 << 5
+	}
+	if i.Bold != 0 {
+		bits |= 1 << 4
+	}
+
+	info := le.AppendUint16(nil, uint16(int16(i.Size)))
+	info = append(info, bits, 0)
+	info = le.AppendUint16(info, uint16(i.StretchH))
+	info = append(info, byte(i.AA))
+	// padding up, right, down, left
+	info = append(info, 0, 0, 0, 0)
+	info = append(info, byte(i.spacing[0]), byte(i.spacing[1]), byte(i.Outline))
+	info = append(info, i.Face...)
+	info = append(info, 0)
+	block(1, info)
+
+	c := f.Common
+	common := le.AppendUint16(nil, uint16(c.LineHeight))
+	common = le.AppendUint16(common, uint16(c.Base))
+	common = le.AppendUint16(common, uint16(c.ScaleW))
+	common = le.AppendUint16(common, uint16(c.ScaleH))
+	common = le.AppendUint16(common, uint16(c.Pages))
+	common = append(common, 0, byte(c.AlphaChnl), byte(c.RedChnl), byte(c.GreenChnl), byte(c.BlueChnl))
+	block(2, common)
+
+	var pages []byte
+	for _, p := range f.Pages {
+		pages = append(pages, p.File...)
+		pages = append(pages, 0)
+	}
+	block(3, pages)
+
+	var chars []byte
+	for _, ch := range f.Chars.Chars {
+		chars = le.AppendUint32(chars, uint32(ch.ID))
+		chars = le.AppendUint16(chars, uint16(ch.X))
+		chars = le.AppendUint16(chars, uint16(ch.Y))
+		chars = le.AppendUint16(chars, uint16(ch.Width))
+		chars = le.AppendUint16(chars, uint16(ch.Height))
+		chars = le.AppendUint16(chars, uint16(int16(ch.XOffset)))
+		chars = le.AppendUint16(chars, uint16(int16(ch.YOffset)))
+		chars = le.AppendUint16(chars, uint16(int16(ch.XAdvance)))
+		chars = append(chars, byte(ch.Page), byte(ch.Chnl))
+	}
+	block(4, chars)
+
+	if f.Kernings.Count > 0 {
+		var kernings []byte
+		for _, k := range f.Kernings.Kernings {
+			kernings = le.AppendUint32(kernings, uint32(k.First))
+			kernings = le.AppendUint32(kernings, uint32(k.Second))
+			kernings = le.AppendUint16(kernings, uint16(int16(k.Amount)))
+		}
+		block(5, kernings)
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
@@ -0,0 +1,52 @@
+package msdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBMFontMultiplePages(t *testing.T) {
+	m := newTestMsdf(t, &Config{PixelsPerEm: 32})
+
+	atlas, err := m.Atlas([]rune("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"), &AtlasConfig{
+		Padding:   2,
+		MaxWidth:  128,
+		MaxHeight: 128,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(atlas.Pages) < 2 {
+		t.Fatalf("%d pages, want more than one", len(atlas.Pages))
+	}
+
+	size := atlas.Pages[0].Image().Bounds().Size()
+	for i, p := range atlas.Pages {
+		if s := p.Image().Bounds().Size(); s != size {
+			t.Errorf("page %d is %v, page 0 is %v", i, s, size)
+		}
+	}
+
+	var names []string
+	for i := range atlas.Pages {
+		names = append(names, fmt.Sprintf("atlas_%d.png", i))
+	}
+
+	var b bytes.Buffer
+	if err := atlas.WriteBMFont(&b, BMFontText, names); err != nil {
+		t.Fatal(err)
+	}
+
+	common := fmt.Sprintf(" scaleW=%d scaleH=%d pages=%d ", size.X, size.Y, len(atlas.Pages))
+	if !strings.Contains(b.String(), common) {
+		t.Errorf("descriptor does not contain %q:\n%s", common, b.String())
+	}
+
+	for _, g := range atlas.Glyphs {
+		if g.Rect.Max.X > size.X || g.Rect.Max.Y > size.Y {
+			t.Errorf("glyph %q at %v is outside its %v page", g.Rune, g.Rect, size)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package msdf
 
 import (
 	"image/color"
-	"math"
 	"strings"
 )
 
@@ -21,36 +20,29 @@ const (
 
 var pallete = []EdgeColor{CYAN, MAGENTA, YELLOW}
 
-func colorize(contours []*Contour, seed uint) {
-	thershold := math.Sin(3)
-	var corners []int
+func colorize(contours []*Contour, angle float64, seed uint) {
 	color := initColor(&seed)
 
 	for _, contour := range contours {
-		edges := contour.Edges
-
-		corners = []int{}
-		prevEdge := edges[0]
-		for i, edge := range edges {
-			isCorner := prevEdge.Curve.IsCorner(edge.Curve, thershold)
-			if isCorner {
-				corners = append(corners, i)
-			}
-
-			prevEdge = edge
+		if len(contour.Edges) == 0 {
+			continue
 		}
 
+		corners := findCorners(contour.Edges, angle)
+
 		// smooth edge
 		if len(corners) == 0 {
-			// TODO: smooth edge case
+			for _, edge := range contour.Edges {
+				edge.Color = WHITE
+			}
 
 			// teardrop case
 		} else if len(corners) == 1 {
-			// TODO: add teardrp case
+			colorTeardrop(contour, corners[0], &color, &seed)
 
 			// multiple corners
 		} else {
-			cornerCount := len(contours)
+			cornerCount := len(corners)
 			spline := 0
 			start := corners[0]
 			m := len(contour.Edges)
@@ -74,6 +66,65 @@ func colorize(contours []*Contour, seed uint) {
 
 }
 
+// findCorners returns the indices of the edges that start at a corner.
+func findCorners(edges []*Edge, angle float64) []int {
+	corners := []int{}
+
+	prevEdge := edges[len(edges)-1]
+	for i, edge := range edges {
+		if prevEdge.Curve.IsCorner(edge.Curve, angle) {
+			corners = append(corners, i)
+		}
+		prevEdge = edge
+	}
+
+	return corners
+}
+
+func colorTeardrop(contour *Contour, corner int, color *EdgeColor, seed *uint) {
+	switchColor(color, seed)
+	first := *color
+	switchColor(color, seed)
+	colors := []EdgeColor{first, WHITE, *color}
+
+	edges := teardrop(contour, corner)
+	for i, edge := range edges {
+		edge.Color = colors[1+symmetricalTrichotomy(i, len(edges))]
+	}
+}
+
+// teardrop returns the edges of a contour with a single corner, starting at
+// that corner. Contours with fewer edges than colors get every edge split in
+// thirds.
+func teardrop(contour *Contour, corner int) []*Edge {
+	m := len(contour.Edges)
+
+	var edges []*Edge
+	for i := range m {
+		edges = append(edges, contour.Edges[(corner+i)%m])
+	}
+
+	if m >= 3 {
+		return edges
+	}
+
+	var parts []*Edge
+	for _, edge := range edges {
+		for _, c := range edge.Curve.SplitInThirds() {
+			parts = append(parts, &Edge{id: edge.id, Kind: edge.Kind, Curve: c})
+		}
+	}
+	contour.Edges = parts
+
+	return parts
+}
+
+// symmetricalTrichotomy maps position out of n to -1, 0 or 1 so the middle
+// third gets 0 and the outer thirds are the same size.
+func symmetricalTrichotomy(position, n int) int {
+	return int(3+2.875*float64(position)/float64(n-1)-1.4375+0.5) - 3
+}
+
 func seedExtract2(seed *uint) int {
 	v := int(*seed) & 1
 	*seed = *seed >> 1
@@ -0,0 +1,137 @@
+package msdf
+
+import "testing"
+
+func cubicEdge(p0, p1, p2, p3 Point) *Edge {
+	return &Edge{Kind: "C", Curve: NewCubicBezier(p0, p1, p2, p3)}
+}
+
+func lineEdge(p0, p1 Point) *Edge {
+	return &Edge{Kind: "L", Curve: NewLine(p0, p1)}
+}
+
+// circleContour is a smooth contour, four cubics with matching tangents at
+// every junction.
+func circleContour() *Contour {
+	const k = 0.5523
+	return newContour([]*Edge{
+		cubicEdge(Point{X: 1, Y: 0}, Point{X: 1, Y: k}, Point{X: k, Y: 1}, Point{X: 0, Y: 1}),
+		cubicEdge(Point{X: 0, Y: 1}, Point{X: -k, Y: 1}, Point{X: -1, Y: k}, Point{X: -1, Y: 0}),
+		cubicEdge(Point{X: -1, Y: 0}, Point{X: -1, Y: -k}, Point{X: -k, Y: -1}, Point{X: 0, Y: -1}),
+		cubicEdge(Point{X: 0, Y: -1}, Point{X: k, Y: -1}, Point{X: 1, Y: -k}, Point{X: 1, Y: 0}),
+	})
+}
+
+func squareContour(x, y float64) *Contour {
+	a, b := Point{X: x, Y: y}, Point{X: x + 10, Y: y}
+	c, d := Point{X: x + 10, Y: y + 10}, Point{X: x, Y: y + 10}
+	return newContour([]*Edge{lineEdge(a, b), lineEdge(b, c), lineEdge(c, d), lineEdge(d, a)})
+}
+
+func triangleContour(x, y float64) *Contour {
+	a, b, c := Point{X: x, Y: y}, Point{X: x + 10, Y: y}, Point{X: x + 5, Y: y + 10}
+	return newContour([]*Edge{lineEdge(a, b), lineEdge(b, c), lineEdge(c, a)})
+}
+
+func checkNoClear(t *testing.T, contours []*Contour) {
+	t.Helper()
+	for i, con := range contours {
+		for j, edge := range con.Edges {
+			if edge.Color == CLEAR {
+				t.Errorf("contour %d edge %d is CLEAR", i, j)
+			}
+		}
+	}
+}
+
+func TestColorizeSmoothContour(t *testing.T) {
+	for seed := range uint(9) {
+		contours := []*Contour{circleContour()}
+		colorize(contours, 3, seed)
+
+		for i, edge := range contours[0].Edges {
+			if edge.Color != WHITE {
+				t.Errorf("seed %d: edge %d is %v, want WHITE", seed, i, edge.Color)
+			}
+		}
+		checkNoClear(t, contours)
+	}
+}
+
+func TestColorizeTeardrop(t *testing.T) {
+	// a single corner at the origin, the other junctions are smooth
+	oneEdge := func() *Contour {
+		return newContour([]*Edge{
+			cubicEdge(Point{}, Point{X: 20, Y: 20}, Point{X: 20, Y: -20}, Point{}),
+		})
+	}
+	twoEdges := func() *Contour {
+		return newContour([]*Edge{
+			cubicEdge(Point{}, Point{X: 20, Y: 20}, Point{X: 40, Y: 20}, Point{X: 40, Y: 0}),
+			cubicEdge(Point{X: 40, Y: 0}, Point{X: 40, Y: -20}, Point{X: 20, Y: -20}, Point{}),
+		})
+	}
+
+	tests := []struct {
+		name    string
+		contour func() *Contour
+		parts   int
+	}{
+		{"one edge", oneEdge, 3},
+		{"two edges", twoEdges, 6},
+	}
+
+	for _, tt := range tests {
+		for seed := range uint(9) {
+			contours := []*Contour{tt.contour()}
+			if n := len(findCorners(contours[0].Edges, 3)); n != 1 {
+				t.Fatalf("%s: %d corners, want 1", tt.name, n)
+			}
+
+			colorize(contours, 3, seed)
+
+			edges := contours[0].Edges
+			if len(edges) != tt.parts {
+				t.Fatalf("%s seed %d: %d edges, want %d", tt.name, seed, len(edges), tt.parts)
+			}
+
+			first, last := edges[0].Color, edges[len(edges)-1].Color
+			if first == WHITE || last == WHITE || first == last {
+				t.Errorf("%s seed %d: outer colors %v and %v, want two different non-white colors", tt.name, seed, first, last)
+			}
+
+			colors := []EdgeColor{first, WHITE, last}
+			for i, edge := range edges {
+				want := colors[1+symmetricalTrichotomy(i, len(edges))]
+				if edge.Color != want {
+					t.Errorf("%s seed %d: edge %d is %v, want %v", tt.name, seed, i, edge.Color, want)
+				}
+			}
+			checkNoClear(t, contours)
+		}
+	}
+}
+
+func TestColorizeCorners(t *testing.T) {
+	// the number of contours must not be mistaken for the number of corners
+	for seed := range uint(27) {
+		contours := []*Contour{squareContour(0, 0), triangleContour(20, 0)}
+		colorize(contours, 3, seed)
+
+		for i, con := range contours {
+			edges := con.Edges
+			corners := findCorners(edges, 3)
+			if len(corners) != len(edges) {
+				t.Fatalf("contour %d: %d corners, want %d", i, len(corners), len(edges))
+			}
+
+			for _, c := range corners {
+				prev := edges[(c+len(edges)-1)%len(edges)]
+				if prev.Color == edges[c].Color {
+					t.Errorf("seed %d contour %d: both edges at corner %d are %v", seed, i, c, prev.Color)
+				}
+			}
+		}
+		checkNoClear(t, contours)
+	}
+}
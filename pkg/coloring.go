@@ -0,0 +1,273 @@
+package msdf
+
+import (
+	"math"
+)
+
+// EdgeColorer assigns a color to every edge of a glyph. Edges meeting at a
+// corner sharper than angle (in radians) must not get the same color.
+type EdgeColorer interface {
+	Color(contours []*Contour, angle float64, seed uint)
+}
+
+// SimpleColorer switches color at every corner.
+type SimpleColorer struct{}
+
+func (SimpleColorer) Color(contours []*Contour, angle float64, seed uint) {
+	colorize(contours, angle, seed)
+}
+
+// InkTrapColorer works like SimpleColorer but treats corners around short
+// splines, like ink traps, as minor and colors them so they do not force a
+// color change on the longer splines around them.
+type InkTrapColorer struct{}
+
+type inkTrapCorner struct {
+	index int
+	// prevLength is the length of the spline that ends at this corner
+	prevLength float64
+	minor      bool
+	color      EdgeColor
+}
+
+func (InkTrapColorer) Color(contours []*Contour, angle float64, seed uint) {
+	color := initColor(&seed)
+
+	for _, contour := range contours {
+		edges := contour.Edges
+		if len(edges) == 0 {
+			continue
+		}
+
+		indices := findCorners(edges, angle)
+
+		if len(indices) == 0 {
+			for _, edge := range edges {
+				edge.Color = WHITE
+			}
+			continue
+		}
+
+		if len(indices) == 1 {
+			colorTeardrop(contour, indices[0], &color, &seed)
+			continue
+		}
+
+		corners := make([]inkTrapCorner, len(indices))
+		splineLength := 0.0
+		next := 0
+		for i, edge := range edges {
+			if next < len(indices) && indices[next] == i {
+				corners[next] = inkTrapCorner{index: i, prevLength: splineLength}
+				splineLength = 0
+				next += 1
+			}
+			splineLength += estimateLength(edge.Curve)
+		}
+
+		cornerCount := len(corners)
+		majorCount := cornerCount
+		if cornerCount > 3 {
+			// the spline before the first corner wraps around the end of the contour
+			corners[0].prevLength += splineLength
+			for i := range cornerCount {
+				a := corners[i].prevLength
+				b := corners[(i+1)%cornerCount].prevLength
+				c := corners[(i+2)%cornerCount].prevLength
+				if a > b && b < c {
+					corners[i].minor = true
+					majorCount -= 1
+				}
+			}
+		}
+
+		initialColor := EdgeColor(CLEAR)
+		for i := range corners {
+			if corners[i].minor {
+				continue
+			}
+			majorCount -= 1
+			banned := EdgeColor(CLEAR)
+			if majorCount == 0 {
+				banned = initialColor
+			}
+			switchColorEx(&color, &seed, banned)
+			corners[i].color = color
+			if initialColor == CLEAR {
+				initialColor = color
+			}
+		}
+
+		for i := range corners {
+			if corners[i].minor {
+				nextColor := corners[(i+1)%cornerCount].color
+				corners[i].color = (color & nextColor) ^ WHITE
+			} else {
+				color = corners[i].color
+			}
+		}
+
+		spline := 0
+		start := corners[0].index
+		color = corners[0].color
+		m := len(edges)
+		for i := range m {
+			index := (start + i) % m
+			if spline+1 < cornerCount && corners[spline+1].index == index {
+				spline += 1
+				color = corners[spline].color
+			}
+			edges[index].Color = color
+		}
+	}
+}
+
+// DistanceColorer splits contours into splines at corners and picks colors
+// so splines that are close to each other, even across contours, get
+// different colors whenever possible.
+type DistanceColorer struct{}
+
+func (DistanceColorer) Color(contours []*Contour, angle float64, seed uint) {
+
+	var splines [][]*Edge
+	// pairs of splines meeting at a corner, they must differ
+	var adjacent [][2]int
+
+	for _, contour := range contours {
+		edges := contour.Edges
+		if len(edges) == 0 {
+			continue
+		}
+
+		corners := findCorners(edges, angle)
+		first := len(splines)
+
+		switch len(corners) {
+		case 0:
+			splines = append(splines, edges)
+		case 1:
+			var head, tail []*Edge
+			edges = teardrop(contour, corners[0])
+			for i, edge := range edges {
+				switch symmetricalTrichotomy(i, len(edges)) {
+				case -1:
+					head = append(head, edge)
+				case 0:
+					edge.Color = WHITE
+				case 1:
+					tail = append(tail, edge)
+				}
+			}
+			splines = append(splines, head, tail)
+			adjacent = append(adjacent, [2]int{first, first + 1})
+		default:
+			var spline []*Edge
+			next := 1
+			m := len(edges)
+			for i := range m {
+				index := (corners[0] + i) % m
+				if next < len(corners) && corners[next] == index {
+					splines = append(splines, spline)
+					spline = nil
+					next += 1
+				}
+				spline = append(spline, edges[index])
+			}
+			splines = append(splines, spline)
+
+			n := len(splines) - first
+			for i := range n {
+				adjacent = append(adjacent, [2]int{first + i, first + (i+1)%n})
+			}
+		}
+	}
+
+	n := len(splines)
+	if n == 0 {
+		return
+	}
+
+	distances := make([][]float64, n)
+	conflicts := make([][]bool, n)
+	for i := range n {
+		distances[i] = make([]float64, n)
+		conflicts[i] = make([]bool, n)
+	}
+
+	for i := range n {
+		for j := i + 1; j < n; j++ {
+			d := splineDistance(splines[i], splines[j])
+			distances[i][j], distances[j][i] = d, d
+		}
+	}
+
+	for _, pair := range adjacent {
+		conflicts[pair[0]][pair[1]] = true
+		conflicts[pair[1]][pair[0]] = true
+	}
+
+	offset := seedExtract3(&seed)
+	colors := make([]EdgeColor, n)
+
+	for i := range n {
+		bestCost := math.MaxFloat64
+
+		for k := range pallete {
+			c := pallete[(offset+k)%len(pallete)]
+
+			cost := 0.0
+			allowed := true
+			for j := range i {
+				if colors[j] != c {
+					continue
+				}
+				if conflicts[i][j] {
+					allowed = false
+					break
+				}
+				cost += 1 / math.Max(distances[i][j], 1e-9)
+			}
+
+			if allowed && cost < bestCost {
+				bestCost = cost
+				colors[i] = c
+			}
+		}
+
+		for _, edge := range splines[i] {
+			edge.Color = colors[i]
+		}
+	}
+}
+
+func estimateLength(c Curve) float64 {
+	length := 0.0
+	prev := c.PointAt(0)
+	for i := 1; i <= 4; i++ {
+		p := c.PointAt(float64(i) / 4)
+		length += vec().fromAB(prev, p).Distance()
+		prev = p
+	}
+	return length
+}
+
+func splineDistance(a, b []*Edge) float64 {
+	minDist := math.MaxFloat64
+	for _, ea := range a {
+		for _, eb := range b {
+			minDist = math.Min(minDist, edgeDistance(ea.Curve, eb.Curve))
+			minDist = math.Min(minDist, edgeDistance(eb.Curve, ea.Curve))
+		}
+	}
+	return minDist
+}
+
+// edgeDistance samples a and returns the smallest distance of the samples to b.
+func edgeDistance(a, b Curve) float64 {
+	minDist := math.MaxFloat64
+	for i := 0; i <= 16; i++ {
+		d, _ := b.Distance(a.PointAt(float64(i) / 16))
+		minDist = math.Min(minDist, d)
+	}
+	return minDist
+}
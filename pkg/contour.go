@@ -2,6 +2,8 @@ package msdf
 
 import (
 	"fmt"
+	"math"
+	"sort"
 )
 
 type ClockDirection int
@@ -14,6 +16,9 @@ const (
 type Contour struct {
 	Winding ClockDirection
 	Edges   []*Edge
+	// Reversed is set when the contour was drawn in the wrong direction and
+	// its edges were reversed.
+	Reversed bool
 }
 
 func (m *Msdf) getContours(r rune) ([]*Contour, error) {
@@ -23,33 +28,23 @@ func (m *Msdf) getContours(r rune) ([]*Contour, error) {
 	}
 
 	var cons []*Contour
-	var bag []*Edge
-
-	var a, b *Edge
-
-	for i := range edges {
-		isConnected := false
-		a = edges[i]
-
-		bag = append(bag, a)
-		if i+1 < len(edges) {
-			b = edges[i+1]
-			isConnected = a.Curve.IsConnected(b.Curve)
-		}
-
-		if isConnected {
-			continue
-		}
-
-		ce := make([]*Edge, len(bag))
-		copy(ce, bag)
-		bag = nil
-
+	for _, ce := range edges {
 		cons = append(cons, newContour(ce))
+	}
 
+	if !m.cfg.KeepOrientation {
+		orientContours(cons, m.cfg.FillRule)
 	}
 
-	colorize(cons, m.cfg.Seed)
+	colorer := m.cfg.Coloring
+	if colorer == nil {
+		colorer = SimpleColorer{}
+	}
+	angle, err := m.cfg.cornerAngle()
+	if err != nil {
+		return nil, err
+	}
+	colorer.Color(cons, angle, m.cfg.Seed)
 
 	return cons, nil
 }
@@ -72,8 +67,96 @@ func newContour(edges []*Edge) *Contour {
 	}
 }
 
+// orientContours makes outer contours run in one direction and holes in the
+// other, so the inside of every glyph gets positive distances whatever the
+// convention of the font. Every edge casts a scanline through one of its
+// points and votes for whether its contour is drawn in the right direction
+// where the fill rule says the scanline enters or leaves the glyph. Crossings
+// with the glyph filled on both sides, like a contour nested in another one
+// with the same direction under the nonzero rule, do not vote.
+func orientContours(cons []*Contour, fill FillRule) {
+	// an irrational ratio keeps the scanlines off the vertices
+	ratio := 0.5 * (math.Sqrt(5) - 1)
+
+	type intersection struct {
+		x       float64
+		dy      int
+		contour int
+	}
+
+	votes := make([]int, len(cons))
+	for i, con := range cons {
+		for _, edge := range con.Edges {
+			if edge.Curve.TangentAt(ratio).Y == 0 {
+				continue
+			}
+			y := edge.Curve.PointAt(ratio).Y
+
+			var hits []intersection
+			for j, other := range cons {
+				for _, e := range other.Edges {
+					xs, dys := e.Curve.ScanlineIntersections(y)
+					for k := range xs {
+						hits = append(hits, intersection{xs[k], dys[k], j})
+					}
+				}
+			}
+			sort.Slice(hits, func(a, b int) bool { return hits[a].x < hits[b].x })
+
+			// winding number right after each crossing, the ray from a point
+			// counts the crossings to its right
+			after := make([]int, len(hits))
+			w := 0
+			for k := len(hits) - 1; k >= 0; k-- {
+				after[k] = w
+				w += hits[k].dy
+			}
+
+			for k, hit := range hits {
+				if hit.contour != i {
+					continue
+				}
+
+				before := after[k] + hit.dy
+				enters := !fill.inside(before) && fill.inside(after[k])
+				leaves := fill.inside(before) && !fill.inside(after[k])
+
+				if !enters && !leaves {
+					continue
+				}
+				if enters == (hit.dy < 0) {
+					votes[i] += 1
+				} else {
+					votes[i] -= 1
+				}
+			}
+		}
+	}
+
+	// reversing a contour changes the winding numbers, every vote is cast
+	// on the outline as the font draws it
+	for i, con := range cons {
+		if votes[i] < 0 {
+			con.reverse()
+		}
+	}
+}
+
+func (c *Contour) reverse() {
+	n := len(c.Edges)
+	for i := range n / 2 {
+		c.Edges[i], c.Edges[n-1-i] = c.Edges[n-1-i], c.Edges[i]
+	}
+	for _, edge := range c.Edges {
+		edge.Curve = edge.Curve.Reverse()
+	}
+
+	c.Winding = newContour(c.Edges).Winding
+	c.Reversed = !c.Reversed
+}
+
 func (c Contour) String() string {
-	return fmt.Sprintf("D: %d , E: %v", c.Winding, c.Edges)
+	return fmt.Sprintf("D: %d , E: %v, R: %t", c.Winding, c.Edges, c.Reversed)
 }
 
 func (c ClockDirection) String() string {
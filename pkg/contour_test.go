@@ -0,0 +1,73 @@
+package msdf
+
+import "testing"
+
+func square(x0, y0, x1, y1 float64, reversed bool) *Contour {
+	a, b := Point{X: x0, Y: y0}, Point{X: x1, Y: y0}
+	c, d := Point{X: x1, Y: y1}, Point{X: x0, Y: y1}
+	if reversed {
+		b, d = d, b
+	}
+	return newContour([]*Edge{lineEdge(a, b), lineEdge(b, c), lineEdge(c, d), lineEdge(d, a)})
+}
+
+func TestOrientContoursFlipsInvertedGlyph(t *testing.T) {
+	cons := []*Contour{square(0, 0, 20, 20, false)}
+	orientContours(cons, FillRuleNonZero)
+
+	inverted := []*Contour{square(0, 0, 20, 20, true)}
+	orientContours(inverted, FillRuleNonZero)
+
+	if cons[0].Reversed == inverted[0].Reversed {
+		t.Errorf("a square and the same square drawn the other way are both Reversed = %t", cons[0].Reversed)
+	}
+}
+
+func TestOrientContoursKeepsHoles(t *testing.T) {
+	for _, fill := range []FillRule{FillRuleNonZero, FillRuleEvenOdd} {
+		outer := square(0, 0, 20, 20, false)
+		orientContours([]*Contour{outer}, fill)
+
+		cons := []*Contour{square(0, 0, 20, 20, outer.Reversed), square(5, 5, 15, 15, !outer.Reversed)}
+		orientContours(cons, fill)
+
+		for i, con := range cons {
+			if con.Reversed {
+				t.Errorf("fill rule %d: contour %d of a square with a hole was reversed", fill, i)
+			}
+		}
+	}
+}
+
+func TestOrientContoursNested(t *testing.T) {
+	tests := []struct {
+		fill FillRule
+		// the inner square is filled under nonzero and a hole under even-odd
+		filled bool
+	}{
+		{FillRuleNonZero, true},
+		{FillRuleEvenOdd, false},
+	}
+
+	for _, tt := range tests {
+		outer := square(0, 0, 20, 20, false)
+		orientContours([]*Contour{outer}, tt.fill)
+
+		// both squares run in the direction of an outer contour
+		cons := []*Contour{square(0, 0, 20, 20, outer.Reversed), square(5, 5, 15, 15, outer.Reversed)}
+		orientContours(cons, tt.fill)
+
+		if cons[0].Reversed {
+			t.Errorf("fill rule %d: outer square was reversed", tt.fill)
+		}
+		if cons[1].Reversed == tt.filled {
+			t.Errorf("fill rule %d: inner square Reversed = %t", tt.fill, cons[1].Reversed)
+		}
+
+		cfg := &Config{Mode: SDF, FillRule: tt.fill}
+		d := getDistances(cfg, newEdgeTree(cons), Point{X: 10, Y: 10})
+		if (d[0] > 0) != tt.filled {
+			t.Errorf("fill rule %d: distance at the centre is %v", tt.fill, d[0])
+		}
+	}
+}
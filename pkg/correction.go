@@ -0,0 +1,221 @@
+package msdf
+
+import (
+	"image"
+	"math"
+)
+
+type ErrorCorrection int
+
+const (
+	// ErrorCorrectionEdgePriority fixes clashes only where it does not move edges or corners.
+	ErrorCorrectionEdgePriority ErrorCorrection = iota
+	ErrorCorrectionOff
+	// ErrorCorrectionFull fixes every clash, including the ones at edges and corners.
+	ErrorCorrectionFull
+)
+
+// clashRatio is how much larger than the change of a straight edge a jump
+// between neighbouring texels must be to count as a clash, msdfgen uses the
+// same minimum deviation ratio.
+const clashRatio = 1.11111111111111111
+
+// correctErrors finds texels whose channels clash with a neighbour, so the
+// median flips between them while the true distance does not, and sets all
+// of their channels to the median.
+func (req *glyphRequest) correctErrors(tex *Glyph) {
+	if req.cfg.ErrorCorrection == ErrorCorrectionOff {
+		return
+	}
+
+	pix, stride := texels(tex)
+	if pix == nil {
+		return
+	}
+
+	w, h := req.width, req.height
+	at := func(x, y int) []uint8 {
+		i := y*stride + x*4
+		return pix[i : i+3]
+	}
+
+	// a channel legitimately changes by up to 255/range between neighbouring
+	// texels, plus up to 1 from truncating to uint8. Only larger jumps are
+	// clashes.
+	tx := clashRatio * 255 / req.cfg.distanceRange()
+	ty := tx
+	txy := math.Hypot(tx, ty)
+
+	neighbours := []struct {
+		dx, dy    int
+		threshold float64
+	}{
+		{-1, 0, tx}, {1, 0, tx}, {0, -1, ty}, {0, 1, ty},
+		{-1, -1, txy}, {1, -1, txy}, {-1, 1, txy}, {1, 1, txy},
+	}
+
+	var protected []bool
+	if req.cfg.ErrorCorrection == ErrorCorrectionEdgePriority {
+		protected = protectTexels(at, w, h, req.metrics, req.contours)
+	}
+
+	var clashes []image.Point
+	for y := range h {
+		for x := range w {
+			if protected != nil && protected[y*w+x] {
+				continue
+			}
+
+			for _, n := range neighbours {
+				nx, ny := x+n.dx, y+n.dy
+				if nx < 0 || nx >= w || ny < 0 || ny >= h {
+					continue
+				}
+
+				if detectClash(at(x, y), at(nx, ny), n.threshold) {
+					clashes = append(clashes, image.Point{X: x, Y: y})
+					break
+				}
+			}
+		}
+	}
+
+	for _, p := range clashes {
+		c := at(p.X, p.Y)
+		med := median(c[0], c[1], c[2])
+		c[0], c[1], c[2] = med, med, med
+	}
+}
+
+// detectClash reports whether a and b clash and a is the texel to fix.
+func detectClash(a, b []uint8, threshold float64) bool {
+	a0, a1, a2 := float64(a[0]), float64(a[1]), float64(a[2])
+	b0, b1, b2 := float64(b[0]), float64(b[1]), float64(b[2])
+
+	// order the channel pairs from the biggest to the smallest difference
+	if math.Abs(b0-a0) < math.Abs(b1-a1) {
+		a0, a1 = a1, a0
+		b0, b1 = b1, b0
+	}
+	if math.Abs(b1-a1) < math.Abs(b2-a2) {
+		a1, a2 = a2, a1
+		b1, b2 = b2, b1
+		if math.Abs(b0-a0) < math.Abs(b1-a1) {
+			a0, a1 = a1, a0
+			b0, b1 = b1, b0
+		}
+	}
+
+	return math.Abs(b1-a1) >= threshold &&
+		// b was already equalized
+		!(b0 == b1 && b0 == b2) &&
+		// only the texel farther from the outline is fixed
+		math.Abs(a2-127.5) >= math.Abs(b2-127.5) &&
+		flipsMedian(a, b)
+}
+
+// flipsMedian reports whether the median interpolated between a and b, as a
+// bilinear sampler sees it, crosses the outline when neither texel does. Far
+// from the outline channels jump without any effect on the shape.
+func flipsMedian(a, b []uint8) bool {
+	inside := func(t float64) bool {
+		var c [3]float64
+		for i := range c {
+			c[i] = float64(a[i]) + t*(float64(b[i])-float64(a[i]))
+		}
+		return medianf(c[0], c[1], c[2]) > 127.5
+	}
+
+	ia, ib := inside(0), inside(1)
+	if ia != ib {
+		// the outline passes between them
+		return true
+	}
+
+	// the median only changes course where two channels cross
+	ts := []float64{0.5}
+	for i := range 3 {
+		for j := i + 1; j < 3; j++ {
+			da := float64(a[j]) - float64(a[i])
+			slope := (float64(b[i]) - float64(a[i])) - (float64(b[j]) - float64(a[j]))
+			if slope != 0 {
+				if t := da / slope; t > 0 && t < 1 {
+					ts = append(ts, t)
+				}
+			}
+		}
+	}
+
+	for _, t := range ts {
+		if inside(t) != ia {
+			return true
+		}
+	}
+	return false
+}
+
+// protectTexels marks the texels around corners and the texels the outline
+// passes between, changing them would move the shape.
+func protectTexels(at func(x, y int) []uint8, w, h int, metrics *Metrics, contours []*Contour) []bool {
+	protected := make([]bool, w*h)
+
+	protect := func(x, y int) {
+		if x >= 0 && x < w && y >= 0 && y < h {
+			protected[y*w+x] = true
+		}
+	}
+
+	for _, con := range contours {
+		prev := con.Edges[len(con.Edges)-1]
+		for _, edge := range con.Edges {
+			if edge.Color != prev.Color {
+				p := edge.Curve.PointAt(0)
+				px, py := metrics.ToPixel(p.X, p.Y)
+				x0, y0 := int(math.Floor(px)), int(math.Floor(py))
+
+				// texture rows are flipped
+				for dy := range 2 {
+					for dx := range 2 {
+						protect(x0+dx, h-1-(y0+dy))
+					}
+				}
+			}
+			prev = edge
+		}
+	}
+
+	inside := func(x, y int) bool {
+		c := at(x, y)
+		return median(c[0], c[1], c[2]) > 127
+	}
+
+	for y := range h {
+		for x := range w {
+			if x+1 < w && inside(x, y) != inside(x+1, y) {
+				protect(x, y)
+				protect(x+1, y)
+			}
+			if y+1 < h && inside(x, y) != inside(x, y+1) {
+				protect(x, y)
+				protect(x, y+1)
+			}
+		}
+	}
+
+	return protected
+}
+
+func median(a, b, c uint8) uint8 {
+	return max(min(a, b), min(max(a, b), c))
+}
+
+// texels returns the 4 bytes per pixel buffer of multi-channel textures.
+func texels(g *Glyph) ([]uint8, int) {
+	switch img := g.Image().(type) {
+	case *image.RGBA:
+		return img.Pix, img.Stride
+	case *image.NRGBA:
+		return img.Pix, img.Stride
+	}
+	return nil, 0
+}
@@ -0,0 +1,31 @@
+package msdf
+
+import (
+	"image"
+	"testing"
+)
+
+func TestErrorCorrectionKeepsStraightEdges(t *testing.T) {
+	for _, r := range "IHLT" {
+		var pix [][]byte
+		for _, ec := range []ErrorCorrection{ErrorCorrectionOff, ErrorCorrectionEdgePriority} {
+			m := newTestMsdf(t, &Config{PixelsPerEm: 48, DistanceRange: 4, ErrorCorrection: ec})
+
+			g, err := m.Get(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pix = append(pix, g.Image().(*image.RGBA).Pix)
+		}
+
+		changed := 0
+		for i := range pix[0] {
+			if pix[0][i] != pix[1][i] {
+				changed++
+			}
+		}
+		if changed > 0 {
+			t.Errorf("%q: error correction changed %d channels of a straight-edged glyph", r, changed)
+		}
+	}
+}
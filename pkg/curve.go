@@ -2,8 +2,6 @@ package msdf
 
 import (
 	"math"
-
-	"golang.org/x/image/math/fixed"
 )
 
 type CurveSampler interface {
@@ -14,25 +12,31 @@ type CurveSampler interface {
 
 type Curve interface {
 	CurveSampler
-	IsCorner(c2 Curve, threshold float64) bool
-	IsConnected(c Curve) bool
+	// IsCorner reports whether c2 starts at a corner, that is the direction
+	// of travel turns by more than π - angle (in radians) between the curves.
+	IsCorner(c2 Curve, angle float64) bool
 	GetSignedArea() float64
-	GetLowResPoints() []fixed.Point26_6
-	GetDirectionVector() *Vector
+	GetLowResPoints() []Point
+	// DirectionAt is the direction of travel at t, unlike TangentAt it is not
+	// zero at endpoints with coincident control points.
+	DirectionAt(t float64) Point
+	// Distance returns the distance from q to the nearest point of the curve and its parameter t.
+	Distance(q Point) (float64, float64)
+	// ScanlineIntersections returns the x of every point where the curve
+	// crosses the horizontal line at y and the direction of each crossing,
+	// 1 upwards and -1 downwards.
+	ScanlineIntersections(y float64) ([]float64, []int)
+	SplitInThirds() [3]Curve
+	// Reverse returns the same curve traversed from its end to its start.
+	Reverse() Curve
+	// Bounds returns the corners of a box containing the curve, the box of
+	// its control points.
+	Bounds() (Point, Point)
 }
 
 type baseCurve struct {
-	points       []fixed.Point26_6
-	directionVec *Vector
-}
-
-func (c *baseCurve) IsConnected(c2 Curve) bool {
-
-	N := len(c.points)
-	a := c.points[N-1]
-	s := c2.GetLowResPoints()[0]
-
-	return (s == a)
+	points  []Point
+	sampler CurveSampler
 }
 
 func (c *baseCurve) doLowResSampling(sampler CurveSampler) {
@@ -40,13 +44,10 @@ func (c *baseCurve) doLowResSampling(sampler CurveSampler) {
 	for i := range 65 {
 		t := float64(i) / 64.0
 		p := sampler.PointAt(t)
-		c.points = append(c.points, p.fixed())
+		c.points = append(c.points, p)
 	}
 
-	x0, y0 := unpack_p26_6(c.points[0])
-	x1, y1 := unpack_p26_6(c.points[len(c.points)-1])
-	c.directionVec = &Vector{}
-	c.directionVec.fromXY(x0, y0, x1, y1)
+	c.sampler = sampler
 }
 
 func (c *baseCurve) GetSignedArea() float64 {
@@ -59,41 +60,66 @@ func (c *baseCurve) GetSignedArea() float64 {
 		B := points[i-1]
 		C := points[i+1]
 
-		AB := vec().fromP26_6(A, B)
-		AC := vec().fromP26_6(A, C)
+		AB := vec().fromAB(A, B)
+		AC := vec().fromAB(A, C)
 		sum += AB.Cross(AC)
 	}
 
 	return sum
 }
 
-func (c baseCurve) GetLowResPoints() []fixed.Point26_6 {
+func (c baseCurve) GetLowResPoints() []Point {
 	return c.points
 }
 
-func (c baseCurve) GetDirectionVector() *Vector {
-	return c.directionVec
+func (c *baseCurve) DirectionAt(t float64) Point {
+	if d := c.sampler.TangentAt(t); d.X != 0 || d.Y != 0 {
+		return d
+	}
+
+	// when P0 == P1 the curve leaves the endpoint along its second
+	// derivative, B'(t) ~ B''(0)t at the start and B''(1)(t-1) at the end
+	d := c.sampler.CurvatureAt(t)
+	if t == 1 {
+		d = Point{X: -d.X, Y: -d.Y}
+	}
+	if d.X != 0 || d.Y != 0 {
+		return d
+	}
+
+	p0, p1 := c.sampler.PointAt(0), c.sampler.PointAt(1)
+	return Point{X: p1.X - p0.X, Y: p1.Y - p0.Y}
 }
 
-func (c1 *baseCurve) IsCorner(c2 Curve, threshold float64) bool {
-	v1 := c1.GetDirectionVector().Normalize()
-	v2 := c2.GetDirectionVector().Normalize()
+func (c1 *baseCurve) IsCorner(c2 Curve, angle float64) bool {
+	v1 := vec().fromP(c1.DirectionAt(1)).Normalize()
+	v2 := vec().fromP(c2.DirectionAt(0)).Normalize()
 
-	cross := v1.Cross(v2)
-	dot := v1.Dot(v2)
+	turn := math.Atan2(math.Abs(v1.Cross(v2)), v1.Dot(v2))
 
-	return dot <= 0 || math.Abs(cross) > threshold
+	return turn > math.Pi-angle
 
 }
 
+// controlBounds returns the box of the control points, a Bézier curve lies
+// inside their convex hull.
+func controlBounds(points ...Point) (Point, Point) {
+	lo, hi := points[0], points[0]
+	for _, p := range points[1:] {
+		lo = Point{X: math.Min(lo.X, p.X), Y: math.Min(lo.Y, p.Y)}
+		hi = Point{X: math.Max(hi.X, p.X), Y: math.Max(hi.Y, p.Y)}
+	}
+	return lo, hi
+}
+
 // ------------------
 
 type CubicBezier struct {
-	P0, P1, P2, P3 fixed.Point26_6
+	P0, P1, P2, P3 Point
 	baseCurve
 }
 
-func NewCubicBezier(p0, p1, p2, p3 fixed.Point26_6) *CubicBezier {
+func NewCubicBezier(p0, p1, p2, p3 Point) *CubicBezier {
 	cb := &CubicBezier{
 		P0:        p0,
 		P1:        p1,
@@ -109,10 +135,10 @@ func NewCubicBezier(p0, p1, p2, p3 fixed.Point26_6) *CubicBezier {
 
 func (cb *CubicBezier) TangentAt(t float64) Point {
 
-	x0, y0 := unpack_p26_6(cb.P0)
-	x1, y1 := unpack_p26_6(cb.P1)
-	x2, y2 := unpack_p26_6(cb.P2)
-	x3, y3 := unpack_p26_6(cb.P3)
+	x0, y0 := cb.P0.X, cb.P0.Y
+	x1, y1 := cb.P1.X, cb.P1.Y
+	x2, y2 := cb.P2.X, cb.P2.Y
+	x3, y3 := cb.P3.X, cb.P3.Y
 
 	x := 3*(x1-x0) + 6*t*(x2-2*x1+x0) + 3*math.Pow(t, 2)*(x3-3*x2+3*x1-x0)
 	y := 3*(y1-y0) + 6*t*(y2-2*y1+y0) + 3*math.Pow(t, 2)*(y3-3*y2+3*y1-y0)
@@ -125,10 +151,10 @@ func (cb *CubicBezier) TangentAt(t float64) Point {
 
 func (cb *CubicBezier) CurvatureAt(t float64) Point {
 
-	x0, y0 := unpack_p26_6(cb.P0)
-	x1, y1 := unpack_p26_6(cb.P1)
-	x2, y2 := unpack_p26_6(cb.P2)
-	x3, y3 := unpack_p26_6(cb.P3)
+	x0, y0 := cb.P0.X, cb.P0.Y
+	x1, y1 := cb.P1.X, cb.P1.Y
+	x2, y2 := cb.P2.X, cb.P2.Y
+	x3, y3 := cb.P3.X, cb.P3.Y
 
 	//B''(t) = 6(1-t)(P₂ - 2P₁ + P₀) + 6t(P₃ - 2P₂ + P₁)
 	x := 6*(1-t)*(x2-2*x1+x0) + 6*t*(x3-2*x2+x1)
@@ -138,10 +164,10 @@ func (cb *CubicBezier) CurvatureAt(t float64) Point {
 }
 
 func (cb *CubicBezier) PointAt(t float64) Point {
-	x0, y0 := unpack_p26_6(cb.P0)
-	x1, y1 := unpack_p26_6(cb.P1)
-	x2, y2 := unpack_p26_6(cb.P2)
-	x3, y3 := unpack_p26_6(cb.P3)
+	x0, y0 := cb.P0.X, cb.P0.Y
+	x1, y1 := cb.P1.X, cb.P1.Y
+	x2, y2 := cb.P2.X, cb.P2.Y
+	x3, y3 := cb.P3.X, cb.P3.Y
 
 	T0 := math.Pow(1-t, 3)
 	T1 := math.Pow(1-t, 2) * t * 3
@@ -157,14 +183,55 @@ func (cb *CubicBezier) PointAt(t float64) Point {
 	}
 }
 
+func (cb *CubicBezier) Distance(q Point) (float64, float64) {
+	return getDistance(cb, q, 8)
+}
+
+func (cb *CubicBezier) ScanlineIntersections(y float64) ([]float64, []int) {
+	y0 := cb.P0.Y
+	y1 := cb.P1.Y
+	y2 := cb.P2.Y
+	y3 := cb.P3.Y
+
+	a := -y0 + 3*y1 - 3*y2 + y3
+	b := 3*y0 - 6*y1 + 3*y2
+	c := -3*y0 + 3*y1
+
+	return scanlineIntersections(cb, y, solveCubic(a, b, c, y0-y))
+}
+
+func (cb *CubicBezier) Bounds() (Point, Point) {
+	return controlBounds(cb.P0, cb.P1, cb.P2, cb.P3)
+}
+
+func (cb *CubicBezier) Reverse() Curve {
+	return NewCubicBezier(cb.P3, cb.P2, cb.P1, cb.P0)
+}
+
+func (cb *CubicBezier) split(t float64) (*CubicBezier, *CubicBezier) {
+	p0, p1, p2, p3 := cb.P0, cb.P1, cb.P2, cb.P3
+
+	p01, p12, p23 := lerp(p0, p1, t), lerp(p1, p2, t), lerp(p2, p3, t)
+	p012, p123 := lerp(p01, p12, t), lerp(p12, p23, t)
+	p0123 := lerp(p012, p123, t)
+
+	return NewCubicBezier(p0, p01, p012, p0123), NewCubicBezier(p0123, p123, p23, p3)
+}
+
+func (cb *CubicBezier) SplitInThirds() [3]Curve {
+	a, rest := cb.split(1.0 / 3)
+	b, c := rest.split(0.5)
+	return [3]Curve{a, b, c}
+}
+
 // --------------
 
 type QuadraticBezier struct {
-	P0, P1, P2 fixed.Point26_6
+	P0, P1, P2 Point
 	baseCurve
 }
 
-func NewQuadraticBezier(p0, p1, p2 fixed.Point26_6) *QuadraticBezier {
+func NewQuadraticBezier(p0, p1, p2 Point) *QuadraticBezier {
 	qb := &QuadraticBezier{
 		P0:        p0,
 		P1:        p1,
@@ -179,9 +246,9 @@ func NewQuadraticBezier(p0, p1, p2 fixed.Point26_6) *QuadraticBezier {
 
 func (qb *QuadraticBezier) TangentAt(t float64) Point {
 
-	x0, y0 := unpack_p26_6(qb.P0)
-	x1, y1 := unpack_p26_6(qb.P1)
-	x2, y2 := unpack_p26_6(qb.P2)
+	x0, y0 := qb.P0.X, qb.P0.Y
+	x1, y1 := qb.P1.X, qb.P1.Y
+	x2, y2 := qb.P2.X, qb.P2.Y
 
 	x := 2*(x1-x0) + 2*t*(x2-2*x1+x0)
 	y := 2*(y1-y0) + 2*t*(y2-2*y1+y0)
@@ -194,9 +261,9 @@ func (qb *QuadraticBezier) TangentAt(t float64) Point {
 
 func (qb *QuadraticBezier) PointAt(t float64) Point {
 
-	x0, y0 := unpack_p26_6(qb.P0)
-	x1, y1 := unpack_p26_6(qb.P1)
-	x2, y2 := unpack_p26_6(qb.P2)
+	x0, y0 := qb.P0.X, qb.P0.Y
+	x1, y1 := qb.P1.X, qb.P1.Y
+	x2, y2 := qb.P2.X, qb.P2.Y
 
 	T0 := math.Pow(1-t, 2)
 	T1 := (1 - t) * t * 2
@@ -213,9 +280,9 @@ func (qb *QuadraticBezier) PointAt(t float64) Point {
 
 func (qb *QuadraticBezier) CurvatureAt(t float64) Point {
 
-	x0, y0 := unpack_p26_6(qb.P0)
-	x1, y1 := unpack_p26_6(qb.P1)
-	x2, y2 := unpack_p26_6(qb.P2)
+	x0, y0 := qb.P0.X, qb.P0.Y
+	x1, y1 := qb.P1.X, qb.P1.Y
+	x2, y2 := qb.P2.X, qb.P2.Y
 
 	//B''(t) = 2(P₂ - 2P₁ + P₀)
 	x := 2 * (x2 - 2*x1 + x0)
@@ -224,14 +291,83 @@ func (qb *QuadraticBezier) CurvatureAt(t float64) Point {
 	return Point{X: x, Y: y}
 }
 
+func (qb *QuadraticBezier) Distance(q Point) (float64, float64) {
+
+	x0, y0 := qb.P0.X, qb.P0.Y
+	x1, y1 := qb.P1.X, qb.P1.Y
+	x2, y2 := qb.P2.X, qb.P2.Y
+
+	// B(t) - q = qa + 2t*ab + t^2*br
+	qa := vec().fromXY(q.X, q.Y, x0, y0)
+	ab := vec().fromXY(x0, y0, x1, y1)
+	br := &Vector{X: x2 - 2*x1 + x0, Y: y2 - 2*y1 + y0}
+
+	// (B(t) - q) . B'(t) = 0
+	a := br.Dot(br)
+	b := 3 * ab.Dot(br)
+	c := 2*ab.Dot(ab) + qa.Dot(br)
+	d := qa.Dot(ab)
+
+	minDist := qa.Distance()
+	minT := 0.0
+
+	if end := vec().fromXY(q.X, q.Y, x2, y2).Distance(); end < minDist {
+		minDist, minT = end, 1
+	}
+
+	for _, t := range solveCubic(a, b, c, d) {
+		if t <= 0 || t >= 1 {
+			continue
+		}
+
+		dist := vec().fromAB(q, qb.PointAt(t)).Distance()
+		if dist < minDist {
+			minDist, minT = dist, t
+		}
+	}
+
+	return minDist, minT
+}
+
+func (qb *QuadraticBezier) ScanlineIntersections(y float64) ([]float64, []int) {
+	y0 := qb.P0.Y
+	y1 := qb.P1.Y
+	y2 := qb.P2.Y
+
+	return scanlineIntersections(qb, y, solveQuadratic(y0-2*y1+y2, 2*(y1-y0), y0-y))
+}
+
+func (qb *QuadraticBezier) Bounds() (Point, Point) {
+	return controlBounds(qb.P0, qb.P1, qb.P2)
+}
+
+func (qb *QuadraticBezier) Reverse() Curve {
+	return NewQuadraticBezier(qb.P2, qb.P1, qb.P0)
+}
+
+func (qb *QuadraticBezier) split(t float64) (*QuadraticBezier, *QuadraticBezier) {
+	p0, p1, p2 := qb.P0, qb.P1, qb.P2
+
+	p01, p12 := lerp(p0, p1, t), lerp(p1, p2, t)
+	p012 := lerp(p01, p12, t)
+
+	return NewQuadraticBezier(p0, p01, p012), NewQuadraticBezier(p012, p12, p2)
+}
+
+func (qb *QuadraticBezier) SplitInThirds() [3]Curve {
+	a, rest := qb.split(1.0 / 3)
+	b, c := rest.split(0.5)
+	return [3]Curve{a, b, c}
+}
+
 // --------------------
 
 type Line struct {
-	P0, P1 fixed.Point26_6
+	P0, P1 Point
 	baseCurve
 }
 
-func NewLine(p0, p1 fixed.Point26_6) *Line {
+func NewLine(p0, p1 Point) *Line {
 	ln := &Line{
 		P0:        p0,
 		P1:        p1,
@@ -244,8 +380,8 @@ func NewLine(p0, p1 fixed.Point26_6) *Line {
 }
 
 func (ln *Line) PointAt(t float64) Point {
-	x0, y0 := unpack_p26_6(ln.P0)
-	x1, y1 := unpack_p26_6(ln.P1)
+	x0, y0 := ln.P0.X, ln.P0.Y
+	x1, y1 := ln.P1.X, ln.P1.Y
 
 	x := x0 + t*(x1-x0)
 	y := y0 + t*(y1-y0)
@@ -257,8 +393,8 @@ func (ln *Line) PointAt(t float64) Point {
 }
 
 func (l *Line) TangentAt(t float64) Point {
-	x0, y0 := unpack_p26_6(l.P0)
-	x1, y1 := unpack_p26_6(l.P1)
+	x0, y0 := l.P0.X, l.P0.Y
+	x1, y1 := l.P1.X, l.P1.Y
 	return Point{
 		X: x1 - x0,
 		Y: y1 - y0,
@@ -268,3 +404,43 @@ func (l *Line) TangentAt(t float64) Point {
 func (l *Line) CurvatureAt(t float64) Point {
 	return Point{X: 0, Y: 0}
 }
+
+func (l *Line) Distance(q Point) (float64, float64) {
+	x0, y0 := l.P0.X, l.P0.Y
+	x1, y1 := l.P1.X, l.P1.Y
+
+	ab := vec().fromXY(x0, y0, x1, y1)
+	aq := vec().fromXY(x0, y0, q.X, q.Y)
+
+	t := 0.0
+	if length := ab.Dot(ab); length > 0 {
+		t = clamp(aq.Dot(ab)/length, 0, 1)
+	}
+
+	return vec().fromAB(q, l.PointAt(t)).Distance(), t
+}
+
+func (l *Line) ScanlineIntersections(y float64) ([]float64, []int) {
+	y0 := l.P0.Y
+	y1 := l.P1.Y
+
+	var roots []float64
+	if y0 != y1 {
+		roots = []float64{(y - y0) / (y1 - y0)}
+	}
+	return scanlineIntersections(l, y, roots)
+}
+
+func (l *Line) Bounds() (Point, Point) {
+	return controlBounds(l.P0, l.P1)
+}
+
+func (l *Line) Reverse() Curve {
+	return NewLine(l.P1, l.P0)
+}
+
+func (l *Line) SplitInThirds() [3]Curve {
+	a := l.PointAt(1.0 / 3)
+	b := l.PointAt(2.0 / 3)
+	return [3]Curve{NewLine(l.P0, a), NewLine(a, b), NewLine(b, l.P1)}
+}
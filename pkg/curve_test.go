@@ -0,0 +1,100 @@
+package msdf
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// sampledDistance is the distance from q to the nearest of steps+1 evenly
+// spaced points of c.
+func sampledDistance(c CurveSampler, q Point, steps int) float64 {
+	best := math.Inf(1)
+	for i := 0; i <= steps; i++ {
+		p := c.PointAt(float64(i) / float64(steps))
+		best = math.Min(best, math.Hypot(q.X-p.X, q.Y-p.Y))
+	}
+	return best
+}
+
+func TestCubicDistanceMatchesSampling(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	point := func() Point {
+		return Point{X: rng.Float64()*200 - 100, Y: rng.Float64()*200 - 100}
+	}
+
+	var curves []*CubicBezier
+	for range 5000 {
+		curves = append(curves, NewCubicBezier(point(), point(), point(), point()))
+	}
+
+	// degenerate cubics
+	for range 200 {
+		p0, p1, p2, p3 := point(), point(), point(), point()
+		curves = append(curves,
+			NewCubicBezier(p0, p0, p2, p3),
+			NewCubicBezier(p0, p1, p3, p3),
+			NewCubicBezier(p0, p0, p3, p3),
+			NewCubicBezier(p0, p0, p0, p0),
+			// collinear, in order and folding back over itself
+			NewCubicBezier(p0, lerp(p0, p3, 1.0/3), lerp(p0, p3, 2.0/3), p3),
+			NewCubicBezier(p0, lerp(p0, p3, 1.5), lerp(p0, p3, -0.5), p3),
+		)
+	}
+
+	for i, c := range curves {
+		q := point()
+		d, ct := c.Distance(q)
+
+		if ct < 0 || ct > 1 {
+			t.Fatalf("curve %d %v: t = %v, want it in [0, 1]", i, c, ct)
+		}
+
+		p := c.PointAt(ct)
+		if got := math.Hypot(q.X-p.X, q.Y-p.Y); math.Abs(got-d) > 1e-9 {
+			t.Fatalf("curve %d %v: distance %v does not match the point at t = %v, %v", i, c, d, ct, got)
+		}
+
+		if sampled := sampledDistance(c, q, 4000); d > sampled+1e-9 {
+			t.Fatalf("curve %d %v: distance to %v is %v, dense sampling found %v", i, c, q, d, sampled)
+		}
+	}
+}
+
+func TestIsCorner(t *testing.T) {
+	deg := math.Pi / 180
+	in := NewLine(Point{X: -1, Y: 0}, Point{})
+
+	tests := []struct {
+		turn, angle float64
+		want        bool
+	}{
+		{10 * deg, 3, true},
+		{5 * deg, 3, false},
+		{100 * deg, 60 * deg, false},
+		{130 * deg, 60 * deg, true},
+		{100 * deg, 100 * deg, true},
+		{170 * deg, math.Pi, true},
+		{0, math.Pi, false},
+	}
+
+	for _, tt := range tests {
+		out := NewLine(Point{}, Point{X: math.Cos(tt.turn), Y: math.Sin(tt.turn)})
+		if got := in.IsCorner(out, tt.angle); got != tt.want {
+			t.Errorf("turn %.0f° with angle %.0f°: IsCorner = %t, want %t", tt.turn/deg, tt.angle/deg, got, tt.want)
+		}
+	}
+}
+
+func TestCornerAngleRange(t *testing.T) {
+	for _, angle := range []float64{-1, 200 * math.Pi / 180, 4} {
+		if _, err := (&Config{CornerAngle: angle}).cornerAngle(); err == nil {
+			t.Errorf("corner angle %v accepted", angle)
+		}
+	}
+	for _, angle := range []float64{0, 0.5, 3, math.Pi} {
+		if _, err := (&Config{CornerAngle: angle}).cornerAngle(); err != nil {
+			t.Errorf("corner angle %v: %v", angle, err)
+		}
+	}
+}
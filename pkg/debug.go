@@ -12,6 +12,19 @@ import (
 
 func (c *Contour) Debug(g *Glyph, m *Metrics) {
 	count := 0
+
+	if c.Reversed && len(c.Edges) > 0 {
+		// mark contours drawn in the wrong direction by the font
+		px, py := m.Scale(c.Edges[0].Curve.GetLowResPoints()[0], g.Image().Bounds(), 40)
+		d := &font.Drawer{
+			Dst:  g.Image(),
+			Src:  image.NewUniform(color.RGBA{255, 255, 0, 255}),
+			Face: basicfont.Face7x13,
+			Dot:  fixed.Point26_6{X: fixed.I(px), Y: fixed.I(py - 5)},
+		}
+		d.DrawString("reversed")
+	}
+
 	for _, edge := range c.Edges {
 
 		points := edge.Curve.GetLowResPoints()
@@ -57,7 +70,7 @@ func (c *Contour) Debug(g *Glyph, m *Metrics) {
 	}
 }
 
-func (m *Msdf) Debug(r rune, tex *Glyph) {
+func (m *Msdf) Debug(r rune, tex *Glyph) error {
 	out := NewGlyph(512, 512)
 
 	texBounds := tex.Image().Bounds()
@@ -68,9 +81,9 @@ func (m *Msdf) Debug(r rune, tex *Glyph) {
 			srcY := int(float64(y) * float64(texBounds.Dy()) / 512.0)
 
 			if srcX < texBounds.Max.X && srcY < texBounds.Max.Y {
-				r, g, b, _ := tex.Image().At(srcX, srcY).RGBA()
+				c := color.NRGBAModel.Convert(tex.Image().At(srcX, srcY)).(color.NRGBA)
 
-				channels := []uint32{r, g, b}
+				channels := []uint8{c.R, c.G, c.B}
 				if channels[0] > channels[1] {
 					channels[0], channels[1] = channels[1], channels[0]
 				}
@@ -81,12 +94,11 @@ func (m *Msdf) Debug(r rune, tex *Glyph) {
 					channels[0], channels[1] = channels[1], channels[0]
 				}
 
-				median := uint8(channels[1] >> 8)
+				median := channels[1]
 				out.Image().Set(x, y, color.RGBA{median, median, median, 255})
 			}
 		}
 	}
 
-	out.Save(fmt.Sprintf("%s/%c_render.png", m.cfg.Debug, r))
-
+	return out.Save(fmt.Sprintf("%s/%c_render.png", m.cfg.Debug, r))
 }
@@ -15,77 +15,113 @@ type Edge struct {
 	Curve Curve
 }
 
-func (m *Msdf) getEdges(r rune) ([]*Edge, error) {
+// getEdges returns the edges of every contour of the glyph. A MoveTo starts a
+// new contour, a contour the font leaves open is closed with a line back to
+// its start and edges of zero length are dropped.
+func (m *Msdf) getEdges(r rune) ([][]*Edge, error) {
+	var contours [][]*Edge
 	var edges []*Edge
 
-	segments, _, err := m.getVector(r)
+	segments, _, _, err := m.getVector(r)
 	if err != nil {
 		return nil, err
 	}
 
-	var p0 fixed.Point26_6
+	var start, p0 Point
 
 	idx := 0
+	add := func(kind string, c Curve) {
+		if lo, hi := c.Bounds(); lo == hi {
+			return
+		}
+		edges = append(edges, &Edge{
+			id:    idx,
+			Kind:  kind,
+			Curve: c,
+		})
+		idx += 1
+	}
+
+	closeContour := func() {
+		if p0 != start {
+			add("L", NewLine(p0, start))
+		}
+		if len(edges) > 0 {
+			contours = append(contours, edges)
+		}
+		edges = nil
+	}
+
 	for _, segment := range segments {
 		args := segment.Args
 		switch segment.Op {
 		case sfnt.SegmentOpMoveTo:
-			p0 = args[0]
+			closeContour()
+			start = fontPoint(args[0])
+			p0 = start
 		case sfnt.SegmentOpLineTo:
-
-			edges = append(edges, &Edge{
-				id:    idx,
-				Kind:  "L",
-				Curve: NewLine(p0, args[0]),
-			})
-			p0 = args[0]
+			p1 := fontPoint(args[0])
+			add("L", NewLine(p0, p1))
+			p0 = p1
 		case sfnt.SegmentOpCubeTo:
-			edges = append(edges, &Edge{
-				id:    idx,
-				Kind:  "C",
-				Curve: NewCubicBezier(p0, args[0], args[1], args[2]),
-			})
-			p0 = args[2]
+			p3 := fontPoint(args[2])
+			add("C", NewCubicBezier(p0, fontPoint(args[0]), fontPoint(args[1]), p3))
+			p0 = p3
 		case sfnt.SegmentOpQuadTo:
-			edges = append(edges, &Edge{
-				id:    idx,
-				Kind:  "Q",
-				Curve: NewQuadraticBezier(p0, args[0], args[1]),
-			})
-			p0 = args[1]
+			p2 := fontPoint(args[1])
+			add("Q", NewQuadraticBezier(p0, fontPoint(args[0]), p2))
+			p0 = p2
 
 		}
-		idx += 1
 
 	}
+	closeContour()
+
+	if len(contours) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrEmptyGlyph, r)
+	}
 
-	return edges, nil
+	return contours, nil
 
 }
 
-func (m *Msdf) getVector(r rune) (sfnt.Segments, fixed.Rectangle26_6, error) {
+// unitPPEM is the size to load outlines and metrics at so they come back in
+// font units. sfnt scales v font units to v*ppem/UnitsPerEm in 26.6 fixed
+// point, so a ppem whose raw value is UnitsPerEm, UnitsPerEm/64 pixels per
+// em, makes every raw 26.6 value exactly v and nothing is rounded.
+func (m *Msdf) unitPPEM() fixed.Int26_6 {
+	return fixed.Int26_6(m.font.UnitsPerEm())
+}
+
+// getVector returns the outline of the glyph with its bounds and advance, all
+// in font units.
+func (m *Msdf) getVector(r rune) (sfnt.Segments, fixed.Rectangle26_6, fixed.Int26_6, error) {
 
-	ppem := fixed.I(12)
+	ppem := m.unitPPEM()
 
 	var buff sfnt.Buffer
 	gi, err := m.font.GlyphIndex(&buff, r)
 	if err != nil {
-		return nil, fixed.Rectangle26_6{}, err
+		return nil, fixed.Rectangle26_6{}, 0, err
+	}
+	if gi == 0 {
+		return nil, fixed.Rectangle26_6{}, 0, fmt.Errorf("%w: %q", ErrGlyphNotFound, r)
 	}
 
 	segments, err := m.font.LoadGlyph(&buff, gi, ppem, nil)
 	if err != nil {
-		return nil, fixed.Rectangle26_6{}, err
+		return nil, fixed.Rectangle26_6{}, 0, err
 	}
 
-	bounds, _, err := m.font.GlyphBounds(&buff, gi, ppem, font.HintingNone)
+	bounds, advance, err := m.font.GlyphBounds(&buff, gi, ppem, font.HintingNone)
 	if err != nil {
-		return nil, fixed.Rectangle26_6{}, err
+		return nil, fixed.Rectangle26_6{}, 0, err
 	}
 
-	return segments, bounds, nil
+	return segments, bounds, advance, nil
 
 }
+
 func (e *Edge) ID() string {
 	return fmt.Sprintf("%s%d", e.Kind, e.id)
 }
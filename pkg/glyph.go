@@ -9,10 +9,12 @@ import (
 )
 
 type Glyph struct {
-	img *image.RGBA
+	img draw.Image
+	// Metrics is set on the textures returned by Get.
+	Metrics *GlyphMetrics
 }
 
-func NewGlyph(height, width int) *Glyph {
+func NewGlyph(width, height int) *Glyph {
 	o := &Glyph{}
 
 	o.img = image.NewRGBA(image.Rect(0, 0, width, height))
@@ -22,12 +24,37 @@ func NewGlyph(height, width int) *Glyph {
 	return o
 }
 
-func (o *Glyph) Save(s string) {
-	file, _ := os.Create(s)
+func NewGrayGlyph(width, height int) *Glyph {
+	return &Glyph{
+		img: image.NewGray(image.Rect(0, 0, width, height)),
+	}
+}
+
+func newTexture(mode Mode, width, height int) *Glyph {
+	switch mode {
+	case SDF, PSDF:
+		return NewGrayGlyph(width, height)
+	case MTSDF:
+		// alpha holds data, it must not be premultiplied into the color channels
+		return &Glyph{img: image.NewNRGBA(image.Rect(0, 0, width, height))}
+	}
+	return NewGlyph(width, height)
+}
+
+func (o *Glyph) Save(s string) error {
+	file, err := os.Create(s)
+	if err != nil {
+		return err
+	}
 	defer file.Close()
-	png.Encode(file, o.img)
+
+	if err := png.Encode(file, o.img); err != nil {
+		return err
+	}
+
+	return file.Close()
 }
 
-func (o *Glyph) Image() *image.RGBA {
+func (o *Glyph) Image() draw.Image {
 	return o.img
 }
@@ -0,0 +1,132 @@
+package msdf
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+// The layout below follows the JSON written by msdf-atlas-gen.
+
+type jsonAtlas struct {
+	Atlas   jsonAtlasInfo `json:"atlas"`
+	Metrics jsonMetrics   `json:"metrics"`
+	Glyphs  []jsonGlyph   `json:"glyphs"`
+	Kerning []jsonKerning `json:"kerning"`
+}
+
+type jsonAtlasInfo struct {
+	Type          string  `json:"type"`
+	DistanceRange float64 `json:"distanceRange"`
+	Size          float64 `json:"size"`
+	Width         int     `json:"width"`
+	Height        int     `json:"height"`
+	YOrigin       string  `json:"yOrigin"`
+}
+
+type jsonMetrics struct {
+	EmSize             float64 `json:"emSize"`
+	LineHeight         float64 `json:"lineHeight"`
+	Ascender           float64 `json:"ascender"`
+	Descender          float64 `json:"descender"`
+	UnderlineY         float64 `json:"underlineY"`
+	UnderlineThickness float64 `json:"underlineThickness"`
+}
+
+type jsonGlyph struct {
+	Unicode     rune        `json:"unicode"`
+	Advance     float64     `json:"advance"`
+	PlaneBounds *jsonBounds `json:"planeBounds,omitempty"`
+	AtlasBounds *jsonBounds `json:"atlasBounds,omitempty"`
+}
+
+type jsonBounds struct {
+	Left   float64 `json:"left"`
+	Bottom float64 `json:"bottom"`
+	Right  float64 `json:"right"`
+	Top    float64 `json:"top"`
+}
+
+type jsonKerning struct {
+	Unicode1 rune    `json:"unicode1"`
+	Unicode2 rune    `json:"unicode2"`
+	Advance  float64 `json:"advance"`
+}
+
+func (a *Atlas) SaveJSON(s string) error {
+	data, err := a.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s, data, 0644)
+}
+
+func (a *Atlas) MarshalJSON() ([]byte, error) {
+	if len(a.Pages) != 1 {
+		return nil, errors.New("json export needs an atlas with exactly one page")
+	}
+
+	l, err := a.layout()
+	if err != nil {
+		return nil, err
+	}
+
+	page := a.Pages[0].Image().Bounds()
+
+	out := jsonAtlas{
+		Atlas: jsonAtlasInfo{
+			Type:          a.msdf.cfg.Mode.String(),
+			DistanceRange: l.distanceRange,
+			Size:          l.size,
+			Width:         page.Dx(),
+			Height:        page.Dy(),
+			YOrigin:       "bottom",
+		},
+		Metrics: jsonMetrics{
+			EmSize:             1,
+			LineHeight:         l.lineHeight,
+			Ascender:           l.ascender,
+			Descender:          l.descender,
+			UnderlineY:         l.underlineY,
+			UnderlineThickness: l.underlineThickness,
+		},
+		Glyphs:  []jsonGlyph{},
+		Kerning: []jsonKerning{},
+	}
+
+	for _, gl := range l.glyphs {
+		jg := jsonGlyph{
+			Unicode: gl.glyph.Rune,
+			Advance: gl.advance,
+		}
+
+		if gl.plane != nil {
+			rect := gl.glyph.Rect
+			jg.PlaneBounds = &jsonBounds{
+				Left:   gl.plane.Left,
+				Bottom: gl.plane.Bottom,
+				Right:  gl.plane.Right,
+				Top:    gl.plane.Top,
+			}
+			jg.AtlasBounds = &jsonBounds{
+				Left:   float64(rect.Min.X),
+				Bottom: float64(page.Dy() - rect.Max.Y),
+				Right:  float64(rect.Max.X),
+				Top:    float64(page.Dy() - rect.Min.Y),
+			}
+		}
+
+		out.Glyphs = append(out.Glyphs, jg)
+	}
+
+	for _, k := range l.kerning {
+		out.Kerning = append(out.Kerning, jsonKerning{
+			Unicode1: k.First,
+			Unicode2: k.Second,
+			Advance:  k.Advance,
+		})
+	}
+
+	return json.MarshalIndent(out, "", "  ")
+}
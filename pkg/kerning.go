@@ -0,0 +1,67 @@
+package msdf
+
+import (
+	"errors"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+)
+
+// KerningPair adjusts the pen position between two glyphs.
+type KerningPair struct {
+	First, Second rune
+	// Advance is added to the advance of First when Second follows it, in em
+	// units. Negative values move the glyphs closer.
+	Advance float64
+}
+
+// Kerning returns every pair of the runes with a non-zero kerning, read from
+// the GPOS table or the kern table when there is no GPOS. Runes the font has
+// no glyph for are skipped.
+func (m *Msdf) Kerning(runes []rune) ([]KerningPair, error) {
+	var buff sfnt.Buffer
+	em := float64(m.font.UnitsPerEm())
+
+	var found []rune
+	var indices []sfnt.GlyphIndex
+
+	seen := map[rune]bool{}
+	for _, r := range runes {
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+
+		gi, err := m.font.GlyphIndex(&buff, r)
+		if err != nil {
+			return nil, err
+		}
+		if gi == 0 {
+			continue
+		}
+
+		found = append(found, r)
+		indices = append(indices, gi)
+	}
+
+	var pairs []KerningPair
+	for i, first := range found {
+		for j, second := range found {
+			k, err := m.font.Kern(&buff, indices[i], indices[j], m.unitPPEM(), font.HintingNone)
+			if errors.Is(err, sfnt.ErrNotFound) {
+				// no kerning for the pair
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if k == 0 {
+				continue
+			}
+
+			pairs = append(pairs, KerningPair{First: first, Second: second, Advance: float64(k) / em})
+		}
+	}
+
+	return pairs, nil
+}
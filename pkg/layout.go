@@ -0,0 +1,70 @@
+package msdf
+
+// atlasLayout holds the font and glyph placement data shared by the atlas exporters.
+// Unless noted otherwise all values are in em units with the y axis pointing up.
+type atlasLayout struct {
+	// size is the number of texture pixels per em
+	size float64
+	// distanceRange is the width of the distance field in texture pixels
+	distanceRange float64
+
+	lineHeight         float64
+	ascender           float64
+	descender          float64
+	underlineY         float64
+	underlineThickness float64
+
+	glyphs  []glyphLayout
+	kerning []KerningPair
+}
+
+type glyphLayout struct {
+	glyph   *AtlasGlyph
+	advance float64
+	plane   *Bounds
+}
+
+func (a *Atlas) layout() (*atlasLayout, error) {
+	m := a.msdf
+
+	fm, err := m.FontMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &atlasLayout{
+		lineHeight:         fm.LineHeight,
+		ascender:           fm.Ascender,
+		descender:          fm.Descender,
+		underlineY:         fm.UnderlineY,
+		underlineThickness: fm.UnderlineThickness,
+	}
+
+	l.size = m.cfg.pixelsPerEm() * m.cfg.scale()
+	l.distanceRange = m.cfg.distanceRange()
+
+	for _, g := range a.Glyphs {
+		gl := glyphLayout{
+			glyph:   g,
+			advance: g.Metrics.Advance,
+		}
+
+		if !g.Rect.Empty() {
+			// the plane is the area covered by the glyph texture
+			gl.plane = &g.Metrics.PlaneBounds
+		}
+
+		l.glyphs = append(l.glyphs, gl)
+	}
+
+	var runes []rune
+	for _, g := range a.Glyphs {
+		runes = append(runes, g.Rune)
+	}
+	l.kerning, err = m.Kerning(runes)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
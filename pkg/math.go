@@ -1,6 +1,8 @@
 package msdf
 
 import (
+	"math"
+
 	"golang.org/x/image/math/fixed"
 )
 
@@ -18,8 +20,19 @@ func sign(s float64) float64 {
 	return 0
 }
 
-func (p Point) fixed() fixed.Point26_6 {
-	return pack_p26_6(p.X, p.Y)
+// nonZeroSign is sign with 0 counted as negative.
+func nonZeroSign(s float64) float64 {
+	if s > 0 {
+		return +1
+	}
+	return -1
+}
+
+func lerp(a, b Point, t float64) Point {
+	return Point{
+		X: a.X + t*(b.X-a.X),
+		Y: a.Y + t*(b.Y-a.Y),
+	}
 }
 
 func clamp(value, min, max float64) float64 {
@@ -32,21 +45,76 @@ func clamp(value, min, max float64) float64 {
 	return value
 }
 
-func unpack_p26_6(p fixed.Point26_6) (float64, float64) {
-	return float64(p.X) / 64.0, float64(p.Y) / 64.0
+// fontPoint converts a point loaded at unitPPEM to font units.
+func fontPoint(p fixed.Point26_6) Point {
+	return Point{X: float64(p.X), Y: float64(p.Y)}
+}
+
+// solveQuadratic returns the real roots of ax^2 + bx + c = 0.
+func solveQuadratic(a, b, c float64) []float64 {
+	if math.Abs(a) < 1e-14 {
+		if math.Abs(b) < 1e-14 {
+			return nil
+		}
+		return []float64{-c / b}
+	}
+
+	dscr := b*b - 4*a*c
+	if dscr > 0 {
+		dscr = math.Sqrt(dscr)
+		return []float64{(-b + dscr) / (2 * a), (-b - dscr) / (2 * a)}
+	}
+	if dscr == 0 {
+		return []float64{-b / (2 * a)}
+	}
+	return nil
 }
 
-func pack_p26_6(x, y float64) fixed.Point26_6 {
-	return fixed.Point26_6{
-		X: fixed.Int26_6(x * 64),
-		Y: fixed.Int26_6(y * 64),
+// solveCubicNormed returns the real roots of x^3 + ax^2 + bx + c = 0.
+func solveCubicNormed(a, b, c float64) []float64 {
+	a2 := a * a
+	q := (a2 - 3*b) / 9
+	r := (a*(2*a2-9*b) + 27*c) / 54
+	r2 := r * r
+	q3 := q * q * q
+
+	a /= 3
+
+	if r2 < q3 {
+		t := math.Acos(clamp(r/math.Sqrt(q3), -1, 1))
+		q = -2 * math.Sqrt(q)
+		return []float64{
+			q*math.Cos(t/3) - a,
+			q*math.Cos((t+2*math.Pi)/3) - a,
+			q*math.Cos((t-2*math.Pi)/3) - a,
+		}
 	}
+
+	A := -sign(r) * math.Cbrt(math.Abs(r)+math.Sqrt(r2-q3))
+	B := 0.0
+	if A != 0 {
+		B = q / A
+	}
+
+	roots := []float64{(A + B) - a}
+	if math.Abs(0.5*math.Sqrt(3)*(A-B)) < 1e-14 {
+		roots = append(roots, -0.5*(A+B)-a)
+	}
+	return roots
 }
 
-func pack_i26_6(f float64) fixed.Int26_6 {
-	return fixed.Int26_6(f * 64)
+// solveCubic returns the real roots of ax^3 + bx^2 + cx + d = 0.
+func solveCubic(a, b, c, d float64) []float64 {
+	if a != 0 {
+		bn := b / a
+		// above this ratio the cubic term is negligible
+		if math.Abs(bn) < 1e6 {
+			return solveCubicNormed(bn, c/a, d/a)
+		}
+	}
+	return solveQuadratic(b, c, d)
 }
 
-func unpack_i26_6(f fixed.Int26_6) float64 {
-	return float64(f) / 64.0
+func medianf(a, b, c float64) float64 {
+	return math.Max(math.Min(a, b), math.Min(math.Max(a, b), c))
 }
@@ -2,64 +2,124 @@ package msdf
 
 import (
 	"image"
+	"math"
 
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
 	"golang.org/x/image/math/fixed"
 )
 
+// Metrics maps outline coordinates, font units y-down like sfnt, to texture
+// pixels. Texture pixels are counted from the bottom left corner and a pixel
+// samples the field at its centre.
 type Metrics struct {
-	bounds fixed.Rectangle26_6
-	config *Config
+	// glyph bounds and advance in font units
+	min, max   Point
+	advance    float64
+	unitsPerEm float64
+	// scale is the number of texture pixels per font unit
+	scale float64
+	// translate moves the outline, in font units with the y axis up, before
+	// it is scaled
+	translate Point
+	// texture size
+	width, height int
 }
 
 func (m *Msdf) getMetrics(r rune) (*Metrics, error) {
 
-	_, bounds, err := m.getVector(r)
+	_, bounds, advance, err := m.getVector(r)
 	if err != nil {
 		return nil, err
 	}
-	metrics := newMetrics(m.cfg, bounds)
+	metrics := newMetrics(m.cfg, bounds, advance, m.font.UnitsPerEm())
 	return metrics, nil
 }
 
-func newMetrics(cfg *Config, bounds fixed.Rectangle26_6) *Metrics {
+// newMetrics takes bounds and advance loaded at unitPPEM.
+func newMetrics(cfg *Config, bounds fixed.Rectangle26_6, advance fixed.Int26_6, upem sfnt.Units) *Metrics {
 	m := &Metrics{}
 
 	// Store original glyph bounds without padding
-	m.bounds = bounds
-	m.config = cfg
+	m.min, m.max = fontPoint(bounds.Min), fontPoint(bounds.Max)
+	m.advance = float64(advance)
+	m.unitsPerEm = float64(upem)
+	m.scale = cfg.pixelsPerEm() * cfg.scale() / m.unitsPerEm
+
+	if cfg.Width > 0 && cfg.Height > 0 {
+		m.width, m.height = cfg.Width, cfg.Height
+		m.translate = Point{X: cfg.Translate.X * m.unitsPerEm, Y: cfg.Translate.Y * m.unitsPerEm}
+		return m
+	}
+
+	// fit the texture to the glyph and half the distance range on every
+	// side, then centre the glyph in it
+	w, h := m.GetRange()
+	pad := cfg.distanceRange()
+
+	m.width = max(int(math.Ceil(w*m.scale+pad)), 1)
+	m.height = max(int(math.Ceil(h*m.scale+pad)), 1)
+
+	left := m.min.X
+	bottom := -m.max.Y
+	m.translate = Point{
+		X: (float64(m.width)/m.scale-w)/2 - left,
+		Y: (float64(m.height)/m.scale-h)/2 - bottom,
+	}
 
 	return m
 }
 
 func (e *Metrics) GetRange() (float64, float64) {
 	// Return the original glyph dimensions
-	rangeX := e.bounds.Max.X - e.bounds.Min.X
-	rangeY := e.bounds.Max.Y - e.bounds.Min.Y
-
-	return unpack_i26_6(rangeX), unpack_i26_6(rangeY)
+	return e.max.X - e.min.X, e.max.Y - e.min.Y
 }
 
+// ToFloat maps the centre of texture pixel (x, y) to outline coordinates.
 func (e *Metrics) ToFloat(x, y int) (float64, float64) {
-	// Simple linear mapping from texture space to glyph coordinate space
-	rangeX, rangeY := e.GetRange()
+	fx := (float64(x)+0.5)/e.scale - e.translate.X
+	fy := (float64(y)+0.5)/e.scale - e.translate.Y
+
+	// outlines are y-down
+	return fx, -fy
+}
+
+// ToPixel is the inverse of ToFloat, it maps glyph coordinates to texture coordinates.
+func (e *Metrics) ToPixel(fx, fy float64) (float64, float64) {
+	x := (fx+e.translate.X)*e.scale - 0.5
+	y := (-fy+e.translate.Y)*e.scale - 0.5
 
-	// Normalize texture coordinates [0, width] -> [0, 1] -> glyph bounds
-	normalizedX := float64(x) / float64(e.config.width)
-	normalizedY := float64(y) / float64(e.config.height)
+	return x, y
+}
 
-	// Map to glyph coordinate space
-	fx := unpack_i26_6(e.bounds.Min.X) + normalizedX*rangeX
-	fy := unpack_i26_6(e.bounds.Max.Y) - normalizedY*rangeY
+// planeBounds returns the area covered by the texture in em units with the
+// y axis up.
+func (e *Metrics) planeBounds() Bounds {
+	return Bounds{
+		Left:   -e.translate.X / e.unitsPerEm,
+		Bottom: -e.translate.Y / e.unitsPerEm,
+		Right:  (float64(e.width)/e.scale - e.translate.X) / e.unitsPerEm,
+		Top:    (float64(e.height)/e.scale - e.translate.Y) / e.unitsPerEm,
+	}
+}
 
-	return fx, fy
+func (e *Metrics) glyphMetrics() *GlyphMetrics {
+	return &GlyphMetrics{
+		Advance:     e.advance / e.unitsPerEm,
+		LeftBearing: e.min.X / e.unitsPerEm,
+		PlaneBounds: e.planeBounds(),
+		AtlasBounds: image.Rect(0, 0, e.width, e.height),
+		// the texture is stored top row first
+		Origin: Point{X: e.translate.X * e.scale, Y: float64(e.height) - e.translate.Y*e.scale},
+	}
 }
 
-func (e *Metrics) Scale(p fixed.Point26_6, bounds image.Rectangle, padding int) (int, int) {
+func (e *Metrics) Scale(p Point, bounds image.Rectangle, padding int) (int, int) {
 	rangeX, rangeY := e.GetRange()
 
 	// Convert from glyph coords back to texture pixel coords
-	normalizedX := unpack_i26_6(p.X-e.bounds.Min.X) / rangeX
-	normalizedY := unpack_i26_6(p.Y-e.bounds.Min.Y) / rangeY
+	normalizedX := (p.X - e.min.X) / rangeX
+	normalizedY := (p.Y - e.min.Y) / rangeY
 
 	w := bounds.Max.X - bounds.Min.X - 2*padding
 	h := bounds.Max.Y - bounds.Min.Y - 2*padding
@@ -69,3 +129,88 @@ func (e *Metrics) Scale(p fixed.Point26_6, bounds image.Rectangle, padding int)
 
 	return px, py
 }
+
+// Bounds is a box in em units with the y axis up.
+type Bounds struct {
+	Left, Bottom, Right, Top float64
+}
+
+// GlyphMetrics places a glyph texture relative to the pen position on the
+// baseline. Lengths are in em units with the y axis up unless noted otherwise,
+// multiply them by the font size to lay out text.
+type GlyphMetrics struct {
+	// Advance moves the pen to the next glyph.
+	Advance float64
+	// LeftBearing is the distance from the pen to the left of the outline.
+	LeftBearing float64
+	// PlaneBounds is the area covered by the texture relative to the pen, it
+	// is the quad the texture is drawn on.
+	PlaneBounds Bounds
+	// AtlasBounds is the glyph inside its texture or atlas page, in pixels
+	// with the origin at the top left.
+	AtlasBounds image.Rectangle
+	// Origin is the pen position in pixels from the top left of AtlasBounds.
+	Origin Point
+}
+
+// GlyphMetrics returns the metrics of the texture Get renders for r. Glyphs
+// without an outline, like space, only have an advance.
+func (m *Msdf) GlyphMetrics(r rune) (*GlyphMetrics, error) {
+	segments, bounds, advance, err := m.getVector(r)
+	if err != nil {
+		return nil, err
+	}
+
+	upem := m.font.UnitsPerEm()
+	if len(segments) == 0 {
+		return &GlyphMetrics{Advance: float64(advance) / float64(upem)}, nil
+	}
+
+	return newMetrics(m.cfg, bounds, advance, upem).glyphMetrics(), nil
+}
+
+// FontMetrics are the font wide values needed to lay out lines of text. All
+// but UnitsPerEm are in em units with the y axis up.
+type FontMetrics struct {
+	UnitsPerEm int
+	Ascender   float64
+	// Descender is below the baseline, so it is usually negative.
+	Descender float64
+	LineGap   float64
+	// LineHeight is the distance between two baselines, the ascender minus
+	// the descender plus the line gap.
+	LineHeight float64
+	CapHeight  float64
+	XHeight    float64
+	// UnderlineY is the top of the underline, negative below the baseline.
+	UnderlineY         float64
+	UnderlineThickness float64
+}
+
+// FontMetrics reads the hhea, OS/2 and post tables of the font.
+func (m *Msdf) FontMetrics() (*FontMetrics, error) {
+	var buff sfnt.Buffer
+	em := float64(m.font.UnitsPerEm())
+
+	fm, err := m.font.Metrics(&buff, m.unitPPEM(), font.HintingNone)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &FontMetrics{
+		UnitsPerEm: int(m.font.UnitsPerEm()),
+		Ascender:   float64(fm.Ascent) / em,
+		Descender:  float64(-fm.Descent) / em,
+		LineGap:    float64(fm.Height-fm.Ascent-fm.Descent) / em,
+		LineHeight: float64(fm.Height) / em,
+		CapHeight:  float64(fm.CapHeight) / em,
+		XHeight:    float64(fm.XHeight) / em,
+	}
+
+	if post := m.font.PostTable(); post != nil {
+		f.UnderlineY = float64(post.UnderlinePosition) / em
+		f.UnderlineThickness = float64(post.UnderlineThickness) / em
+	}
+
+	return f, nil
+}
@@ -0,0 +1,115 @@
+package msdf
+
+import (
+	"image"
+	"math"
+)
+
+// maxRects is a MaxRects bin packer using the bottom-left heuristic, it keeps
+// the used area close to the top left corner so pages can be cropped tightly.
+type maxRects struct {
+	width, height int
+	free          []image.Rectangle
+}
+
+func newMaxRects(width, height int) *maxRects {
+	return &maxRects{
+		width:  width,
+		height: height,
+		free:   []image.Rectangle{image.Rect(0, 0, width, height)},
+	}
+}
+
+func (p *maxRects) insert(w, h int) (image.Rectangle, bool) {
+	bestY, bestX := math.MaxInt, math.MaxInt
+	var best image.Rectangle
+	found := false
+
+	for _, f := range p.free {
+		if f.Dx() < w || f.Dy() < h {
+			continue
+		}
+
+		bottom := f.Min.Y + h
+		if bottom < bestY || (bottom == bestY && f.Min.X < bestX) {
+			bestY, bestX = bottom, f.Min.X
+			best = image.Rect(f.Min.X, f.Min.Y, f.Min.X+w, f.Min.Y+h)
+			found = true
+		}
+	}
+
+	if !found {
+		return image.Rectangle{}, false
+	}
+
+	p.place(best)
+	return best, true
+}
+
+func (p *maxRects) place(used image.Rectangle) {
+	var free []image.Rectangle
+
+	for _, f := range p.free {
+		if !f.Overlaps(used) {
+			free = append(free, f)
+			continue
+		}
+
+		if used.Min.X > f.Min.X {
+			free = append(free, image.Rect(f.Min.X, f.Min.Y, used.Min.X, f.Max.Y))
+		}
+		if used.Max.X < f.Max.X {
+			free = append(free, image.Rect(used.Max.X, f.Min.Y, f.Max.X, f.Max.Y))
+		}
+		if used.Min.Y > f.Min.Y {
+			free = append(free, image.Rect(f.Min.X, f.Min.Y, f.Max.X, used.Min.Y))
+		}
+		if used.Max.Y < f.Max.Y {
+			free = append(free, image.Rect(f.Min.X, used.Max.Y, f.Max.X, f.Max.Y))
+		}
+	}
+
+	p.free = prune(free)
+}
+
+// prune drops every free rectangle that is fully contained in another one.
+func prune(rects []image.Rectangle) []image.Rectangle {
+	var out []image.Rectangle
+
+	for i, a := range rects {
+		contained := false
+		for j, b := range rects {
+			if i == j || !a.In(b) {
+				continue
+			}
+			// keep the first of two identical rectangles
+			if a == b && i < j {
+				continue
+			}
+			contained = true
+			break
+		}
+
+		if !contained {
+			out = append(out, a)
+		}
+	}
+
+	return out
+}
+
+func nextPowerOfTwo(v int) int {
+	p := 1
+	for p < v {
+		p <<= 1
+	}
+	return p
+}
+
+func prevPowerOfTwo(v int) int {
+	p := 1
+	for p<<1 <= v {
+		p <<= 1
+	}
+	return p
+}
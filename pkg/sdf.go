@@ -1,24 +1,118 @@
 package msdf
 
 import (
+	"errors"
 	"fmt"
 	"image/color"
 	"math"
 	"os"
+	"runtime"
+	"sync"
 
 	"golang.org/x/image/font/sfnt"
 )
 
+var (
+	// ErrGlyphNotFound is returned for runes the font has no glyph for.
+	ErrGlyphNotFound = errors.New("glyph not found")
+	// ErrEmptyGlyph is returned for glyphs without an outline, like space.
+	ErrEmptyGlyph = errors.New("glyph has no outline")
+)
+
+// Msdf is safe for concurrent use, Get keeps the state of a glyph in a
+// glyphRequest and only reads the font and the config.
 type Msdf struct {
 	font *sfnt.Font
 	cfg  *Config
 }
 
+type Mode int
+
+const (
+	// MSDF stores a pseudo-distance per edge color in the RGB channels.
+	MSDF Mode = iota
+	// SDF stores the true signed distance in a single channel.
+	SDF
+	// PSDF stores the signed pseudo-distance in a single channel.
+	PSDF
+	// MTSDF stores MSDF in the RGB channels and the true signed distance in alpha.
+	MTSDF
+)
+
 type Config struct {
-	Seed          uint
-	height, width int
-	Scale         float64
-	Debug         string
+	Seed uint
+	// Width and Height of the texture in pixels. When either is 0 the texture
+	// fits the glyph and the distance range around it, and the glyph is
+	// centred in it instead of being moved by Translate.
+	Width, Height int
+	// PixelsPerEm is the font size in texture pixels, 32 when 0.
+	PixelsPerEm float64
+	// DistanceRange is the width of the distance field around the outline in
+	// texture pixels, 2 when 0.
+	DistanceRange float64
+	// Translate moves the outline in em units, with the y axis up, before it
+	// is scaled. With a fixed texture size it puts the baseline of every
+	// glyph at the same height.
+	Translate Point
+	// Scale multiplies PixelsPerEm, 1 when 0.
+	Scale           float64
+	Debug           string
+	Mode            Mode
+	ErrorCorrection ErrorCorrection
+	// Coloring is the edge coloring strategy, SimpleColorer when nil.
+	Coloring EdgeColorer
+	// CornerAngle in radians, in (0, π]. A junction is a corner when the
+	// direction of travel turns by more than π - CornerAngle. Defaults to 3
+	// like msdfgen.
+	CornerAngle float64
+	// FillRule decides which points are inside, it fixes the sign of the
+	// distances around overlapping contours.
+	FillRule FillRule
+	// KeepOrientation keeps contours in the direction the font draws them,
+	// otherwise contours against the convention of the fill rule are
+	// reversed.
+	KeepOrientation bool
+	// Workers is the number of goroutines rendering a glyph, runtime.NumCPU()
+	// when 0. The output does not depend on it.
+	Workers int
+}
+
+func (c *Config) pixelsPerEm() float64 {
+	if c.PixelsPerEm <= 0 {
+		return 32
+	}
+	return c.PixelsPerEm
+}
+
+func (c *Config) distanceRange() float64 {
+	if c.DistanceRange <= 0 {
+		return 2
+	}
+	return c.DistanceRange
+}
+
+func (c *Config) scale() float64 {
+	if c.Scale <= 0 {
+		return 1
+	}
+	return c.Scale
+}
+
+func (c *Config) cornerAngle() (float64, error) {
+	if c.CornerAngle == 0 {
+		return 3, nil
+	}
+	if c.CornerAngle < 0 || c.CornerAngle > math.Pi {
+		return 0, fmt.Errorf("corner angle %v is outside (0, π]", c.CornerAngle)
+	}
+	return c.CornerAngle, nil
+}
+
+func (c *Config) workers() int {
+	if c.Workers <= 0 {
+		return runtime.NumCPU()
+	}
+	return c.Workers
 }
 
 func New(addr string, cfg *Config) (*Msdf, error) {
@@ -43,157 +137,311 @@ func New(addr string, cfg *Config) (*Msdf, error) {
 	return msdf, nil
 }
 
-func (m *Msdf) Get(r rune) *Glyph {
-	metrics, _ := m.getMetrics(r)
-	contours, _ := m.getContours(r)
-
-	w, h := metrics.GetRange()
+// glyphRequest is the state of a single Get call.
+type glyphRequest struct {
+	cfg           *Config
+	width, height int
+	// distanceRange is in outline units
+	distanceRange float64
+	metrics       *Metrics
+	contours      []*Contour
+	tree          *edgeTree
+}
 
-	minSize := 64
-	m.cfg.height = max(int(h), minSize) + int(m.cfg.Scale*100)
-	m.cfg.width = max(int(w), minSize) + int(m.cfg.Scale*100)
+func (m *Msdf) newRequest(r rune) (*glyphRequest, error) {
+	metrics, err := m.getMetrics(r)
+	if err != nil {
+		return nil, err
+	}
 
-	tex := NewGlyph(m.cfg.width, m.cfg.height)
+	contours, err := m.getContours(r)
+	if err != nil {
+		return nil, err
+	}
 
-	for y := range m.cfg.height {
-		for x := range m.cfg.width {
+	return &glyphRequest{
+		cfg:           m.cfg,
+		width:         metrics.width,
+		height:        metrics.height,
+		distanceRange: m.cfg.distanceRange() / metrics.scale,
+		metrics:       metrics,
+		contours:      contours,
+		tree:          newEdgeTree(contours),
+	}, nil
+}
 
-			xi, yi := metrics.ToFloat(x, y)
-			flippedY := m.cfg.height - 1 - y
+func (m *Msdf) Get(r rune) (*Glyph, error) {
+	req, err := m.newRequest(r)
+	if err != nil {
+		return nil, err
+	}
 
-			r := getChannel(m.cfg, contours, RED, xi, yi)
-			g := getChannel(m.cfg, contours, GREEN, xi, yi)
-			b := getChannel(m.cfg, contours, BLUE, xi, yi)
+	tex := req.render()
+	tex.Metrics = req.metrics.glyphMetrics()
 
-			tex.Image().Set(x, flippedY, color.RGBA{r, g, b, 255})
+	if m.cfg.Mode == MSDF || m.cfg.Mode == MTSDF {
+		req.correctErrors(tex)
+	}
 
+	if m.cfg.Debug != "" {
+		dbg := NewGlyph(512, 512)
+		for _, con := range req.contours {
+			con.Debug(dbg, req.metrics)
+		}
+		if err := dbg.Save(fmt.Sprintf("%s/%c_debug.png", m.cfg.Debug, r)); err != nil {
+			return nil, err
 		}
+	}
+
+	return tex, nil
+}
+
+func (req *glyphRequest) render() *Glyph {
+	tex := newTexture(req.cfg.Mode, req.width, req.height)
 
+	channel := func(d float64) uint8 {
+		return getChannel(req.distanceRange, d)
 	}
 
-	if m.cfg.Debug != "" {
-		dbg := NewGlyph(512, 512)
-		for _, con := range contours {
-			con.Debug(dbg, metrics)
+	row := func(y int) {
+		for x := range req.width {
+
+			xi, yi := req.metrics.ToFloat(x, y)
+			flippedY := req.height - 1 - y
+
+			d := getDistances(req.cfg, req.tree, Point{X: xi, Y: yi})
+
+			switch req.cfg.Mode {
+			case SDF, PSDF:
+				tex.Image().Set(x, flippedY, color.Gray{channel(d[0])})
+			case MTSDF:
+				tex.Image().Set(x, flippedY, color.NRGBA{channel(d[0]), channel(d[1]), channel(d[2]), channel(d[3])})
+			default:
+				tex.Image().Set(x, flippedY, color.RGBA{channel(d[0]), channel(d[1]), channel(d[2]), 255})
+			}
+
 		}
-		dbg.Save(fmt.Sprintf("%s/%c_debug.png", m.cfg.Debug, r))
+	}
+
+	// rows are independent, every worker renders its own band of rows and
+	// writes a disjoint part of the texture
+	workers := max(min(req.cfg.workers(), req.height), 1)
+	band := (req.height + workers - 1) / workers
 
+	var wg sync.WaitGroup
+	for start := 0; start < req.height; start += band {
+		end := min(start+band, req.height)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for y := start; y < end; y++ {
+				row(y)
+			}
+		}()
 	}
+	wg.Wait()
+
 	return tex
 }
 
-func getDistance(c Curve, Q Point) (float64, float64) {
+// getDistance finds the nearest point of c to q. The curve is split into
+// segments and each one is refined with Newton-Raphson iterations. When a
+// segment brackets a minimum the iterations fall back to bisection whenever a
+// step leaves the bracket, and the returned t is always in [0, 1].
+func getDistance(c CurveSampler, q Point, segments int) (float64, float64) {
+
+	minDist := vec().fromAB(q, c.PointAt(0)).Distance()
+	minT := 0.0
+
+	if d := vec().fromAB(q, c.PointAt(1)).Distance(); d < minDist {
+		minDist, minT = d, 1
+	}
+
+	t0 := 0.0
+	f0 := distanceSlope(c, q, t0)
+
+	for i := 1; i <= segments; i++ {
+		t1 := float64(i) / float64(segments)
+		f1 := distanceSlope(c, q, t1)
+
+		var t float64
+		switch {
+		case f0 <= 0 && f1 >= 0:
+			t = solveNewtonRaphson(c, q, t0, t1, (t0+t1)/2, true)
+		case f1 > 0:
+			// a minimum hidden in this segment sits next to t1
+			t = solveNewtonRaphson(c, q, t0, t1, t1, false)
+		case f0 < 0:
+			t = solveNewtonRaphson(c, q, t0, t1, t0, false)
+		default:
+			t = solveNewtonRaphson(c, q, t0, t1, (t0+t1)/2, false)
+		}
 
-	candidates := [][]float64{
-		{0, vec().fromAB(Q, c.PointAt(0)).Distance()},
-		{1, vec().fromAB(Q, c.PointAt(1)).Distance()},
+		if d := vec().fromAB(q, c.PointAt(t)).Distance(); d < minDist {
+			minDist, minT = d, t
+		}
+
+		t0, f0 = t1, f1
 	}
-	tStarts := []float64{0.1, 0.3, 0.5, 0.9}
 
-	for _, ts := range tStarts {
-		t := solveNewtonRaphson(c, Q, ts)
-		if 0 <= t && t <= 1 {
-			d := vec().fromAB(Q, c.PointAt(t)).Distance()
-			candidates = append(candidates, []float64{t, d})
-		} else {
+	return minDist, minT
+}
+
+// distanceSlope is the derivative of half the squared distance between q and c(t).
+func distanceSlope(c CurveSampler, q Point, t float64) float64 {
+	qp := vec().fromAB(q, c.PointAt(t))
+	return qp.Dot(vec().fromP(c.TangentAt(t)))
+}
+
+func solveNewtonRaphson(c CurveSampler, q Point, lo, hi, t float64, bracketed bool) float64 {
+
+	for range 32 {
+		qp := vec().fromAB(q, c.PointAt(t))
+		d1 := vec().fromP(c.TangentAt(t))
+		d2 := vec().fromP(c.CurvatureAt(t))
+
+		ft := qp.Dot(d1)
+		fpt := d1.Dot(d1) + qp.Dot(d2)
 
-			for _, s := range []float64{t - 0.1, t, t + 0.1} {
-				if 0 <= s && s <= 1 {
-					d := vec().fromAB(Q, c.PointAt(s)).Distance()
-					candidates = append(candidates, []float64{s, d})
+		if bracketed {
+			if ft < 0 {
+				lo = t
+			} else {
+				hi = t
+			}
+		}
+
+		next := t - ft/fpt
+		if fpt <= 0 || math.IsNaN(next) || next < lo || next > hi {
+			if !bracketed {
+				// heading away from a minimum, or out of the segment
+				if fpt <= 0 || math.IsNaN(next) {
+					return t
 				}
+				next = clamp(next, lo, hi)
+			} else {
+				next = (lo + hi) / 2
 			}
 		}
-	}
 
-	m := math.MaxFloat32
-	res := []float64{0, 0}
-	for _, c := range candidates {
-		if c[1] < m {
-			m = c[1]
-			res = c
+		if math.Abs(next-t) < 1e-12 {
+			return next
 		}
+		t = next
 	}
-	return res[0], res[1]
 
+	return t
 }
 
-func solveNewtonRaphson(c Curve, q Point, ts float64) float64 {
+// signedDistance orders edges by distance, ties between edges sharing an
+// endpoint are resolved in favour of the one most orthogonal to the query.
+type signedDistance struct {
+	distance float64
+	dot      float64
+}
 
-	t := ts
-	for _ = range 10 {
+func (a signedDistance) less(b signedDistance) bool {
+	da, db := math.Abs(a.distance), math.Abs(b.distance)
+	return da < db || (da == db && a.dot < b.dot)
+}
 
-		Q := vec().fromP(q)
-		BT := vec().fromP(c.PointAt(t))
-		BPT := vec().fromP(c.TangentAt(t))
-		BPPT := vec().fromP(c.CurvatureAt(t))
+func getSignedDistance(c Curve, q Point) (signedDistance, float64) {
+	d, t := c.Distance(q)
 
-		ft := BT.Sub(Q).Dot(BPT)
-		fpt := math.Pow(BPT.Distance(), 2) + BT.Sub(Q).Dot(BPPT)
+	pq := vec().fromAB(c.PointAt(t), q)
+	dir := vec().fromP(c.TangentAt(t))
 
-		t0 := t
-		t = t - ft/fpt
+	// q on the tangent line of an endpoint still gets a side, the dot
+	// below makes the edge lose the tie against its neighbour
+	sd := signedDistance{distance: nonZeroSign(dir.Cross(pq)) * d}
 
-		if math.Abs(t-t0) < 1e-8 {
-			break
-		}
+	if (t == 0 || t == 1) && d > 0 && dir.Distance() > 0 {
+		sd.dot = math.Abs(dir.Normalize().Dot(pq.Normalize()))
 	}
 
-	return t //vec().fromAB(q, c.PointAt(t)).Distance()
+	return sd, t
 }
 
-func getChannel(cfg *Config, contours []*Contour, c EdgeColor, x, y float64) uint8 {
+// getPseudoDistance extends the curve along its tangent past the endpoints,
+// when the nearest point is an endpoint the perpendicular distance to that
+// extension is used instead if it is closer.
+func getPseudoDistance(c Curve, q Point, distance, t float64) float64 {
+	if t != 0 && t != 1 {
+		return distance
+	}
 
-	var A *Vector
-	var B *Vector
-	found := false
-	minDist := math.MaxFloat32
-	for _, con := range contours {
-		for _, edge := range con.Edges {
-			curve := edge.Curve
-			if !edge.Color.Has(c) {
-				continue
-			}
+	dir := vec().fromP(c.TangentAt(t))
+	if dir.Distance() == 0 {
+		return distance
+	}
+	dir = dir.Normalize()
 
-			for t := 0.0; t <= 1; t += 0.01 {
+	pq := vec().fromAB(c.PointAt(t), q)
+	ts := pq.Dot(dir)
 
-				p := curve.PointAt(t)
-				a := vec().fromXY(p.X, p.Y, x, y)
+	if (t == 0 && ts < 0) || (t == 1 && ts > 0) {
+		pd := dir.Cross(pq)
+		if math.Abs(pd) <= math.Abs(distance) {
+			return pd
+		}
+	}
 
-				d := A.Distance()
+	return distance
+}
 
-				if d < minDist {
-					found = true
-					minDist = d
-					A = a
-					B = vec().fromP(curve.TangentAt(t))
-				}
-			}
+// getDistances returns the distance of every channel of the mode at q, a
+// channel no edge is colored for gets 0. The pseudo-distance channels extend
+// their nearest edge along its tangent past the endpoints.
+func getDistances(cfg *Config, tree *edgeTree, q Point) []float64 {
+	var colors []EdgeColor
+	var pseudo []bool
+
+	switch cfg.Mode {
+	case SDF, PSDF:
+		// every edge takes part in a single channel field
+		colors, pseudo = []EdgeColor{CLEAR}, []bool{cfg.Mode == PSDF}
+	case MTSDF:
+		colors, pseudo = []EdgeColor{RED, GREEN, BLUE, CLEAR}, []bool{true, true, true, false}
+	default:
+		colors, pseudo = []EdgeColor{RED, GREEN, BLUE}, []bool{true, true, true}
+	}
 
-			// FIXME: fix  Newton Raphson
-			// d, t := getDistance(curve, Point{X: x, Y: y})
-			// if d < minDist {
-			// 	found = true
-			// 	minDist = d
-			// 	p := curve.PointAt(t)
-			// 	A = vec().fromXY(p.X, p.Y, x, y)
-			// 	B = vec().fromP(curve.TangentAt(t))
-			// }
+	d := make([]float64, len(colors))
+	for i, n := range tree.nearest(q, colors) {
+		if n.curve == nil {
+			continue
+		}
 
+		d[i] = n.distance.distance
+		if pseudo[i] {
+			d[i] = getPseudoDistance(n.curve, q, d[i], n.t)
 		}
 	}
 
-	if !found {
-		return 127
+	if cfg.FillRule != FillRuleOff {
+		correctSign(d, cfg.FillRule.inside(tree.winding(q)))
 	}
 
-	distance := sign(B.Cross(A)) * (minDist)
+	return d
+}
 
-	pixelSize := math.Min(float64(cfg.width), float64(cfg.height))
-	distanceRange := (2.0 / pixelSize) * 50
+func getChannel(distanceRange, distance float64) uint8 {
 
 	normalized := (distance / distanceRange) + 0.5
 	clamped := clamp(normalized, 0, 1)
 
 	return uint8(clamped * 255)
 }
+
+func (m Mode) String() string {
+	switch m {
+	case SDF:
+		return "sdf"
+	case PSDF:
+		return "psdf"
+	case MTSDF:
+		return "mtsdf"
+	}
+	return "msdf"
+}
@@ -0,0 +1,67 @@
+package msdf
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func newTestMsdf(t *testing.T, cfg *Config) *Msdf {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "goregular.ttf")
+	if err := os.WriteFile(path, goregular.TTF, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := New(path, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestWorkersDoNotChangeOutput(t *testing.T) {
+	var want []byte
+
+	for _, workers := range []int{1, 2, 3, 7, 64} {
+		m := newTestMsdf(t, &Config{PixelsPerEm: 48, DistanceRange: 4, Workers: workers})
+
+		g, err := m.Get('@')
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pix := g.Image().(*image.RGBA).Pix
+		if want == nil {
+			want = pix
+			continue
+		}
+		if !bytes.Equal(pix, want) {
+			t.Errorf("%d workers render a different texture than 1 worker", workers)
+		}
+	}
+}
+
+func TestGetConcurrent(t *testing.T) {
+	m := newTestMsdf(t, &Config{PixelsPerEm: 24, Workers: 2})
+	runes := []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789@&%")
+
+	errs := make(chan error, len(runes))
+	for _, r := range runes {
+		go func() {
+			_, err := m.Get(r)
+			errs <- err
+		}()
+	}
+
+	for range runes {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
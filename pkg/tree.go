@@ -0,0 +1,214 @@
+package msdf
+
+import (
+	"math"
+	"sort"
+)
+
+const leafSize = 4
+
+// boxEpsilon keeps boxes touching the nearest point, so edges tied with it are
+// still visited whatever the rounding of the box distance.
+const boxEpsilon = 1e-9
+
+// edgeTree is a bounding volume hierarchy over the edges of a glyph. Queries
+// skip every node whose box is farther than the nearest edge found so far, so
+// a pixel only measures the distance to the few edges around it.
+type edgeTree struct {
+	nodes []edgeNode
+	items []edgeItem
+}
+
+type edgeItem struct {
+	edge     *Edge
+	min, max Point
+	// order is the position of the edge in the contours, ties go to the
+	// first edge just like a linear scan
+	order int
+}
+
+type edgeNode struct {
+	min, max Point
+	// colors is the union of the colors of the edges below the node
+	colors      EdgeColor
+	leaf        bool
+	left, right int
+	// items[start:end] are the edges below the node
+	start, end int
+}
+
+// nearestEdge is the nearest edge found for a channel.
+type nearestEdge struct {
+	curve    Curve
+	distance signedDistance
+	t        float64
+	order    int
+}
+
+func newEdgeTree(contours []*Contour) *edgeTree {
+	tree := &edgeTree{}
+
+	for _, con := range contours {
+		for _, edge := range con.Edges {
+			lo, hi := edge.Curve.Bounds()
+			tree.items = append(tree.items, edgeItem{edge: edge, min: lo, max: hi, order: len(tree.items)})
+		}
+	}
+
+	if len(tree.items) > 0 {
+		tree.build(0, len(tree.items))
+	}
+
+	return tree
+}
+
+func (t *edgeTree) build(start, end int) int {
+	n := edgeNode{
+		min:   Point{X: math.Inf(1), Y: math.Inf(1)},
+		max:   Point{X: math.Inf(-1), Y: math.Inf(-1)},
+		start: start,
+		end:   end,
+	}
+
+	items := t.items[start:end]
+	for _, it := range items {
+		n.min = Point{X: math.Min(n.min.X, it.min.X), Y: math.Min(n.min.Y, it.min.Y)}
+		n.max = Point{X: math.Max(n.max.X, it.max.X), Y: math.Max(n.max.Y, it.max.Y)}
+		n.colors |= it.edge.Color
+	}
+
+	index := len(t.nodes)
+	t.nodes = append(t.nodes, n)
+
+	if len(items) <= leafSize {
+		t.nodes[index].leaf = true
+		return index
+	}
+
+	// split at the median centre along the longest side
+	horizontal := n.max.X-n.min.X >= n.max.Y-n.min.Y
+	centre := func(it edgeItem) float64 {
+		if horizontal {
+			return it.min.X + it.max.X
+		}
+		return it.min.Y + it.max.Y
+	}
+	sort.SliceStable(items, func(a, b int) bool {
+		return centre(items[a]) < centre(items[b])
+	})
+
+	mid := (start + end) / 2
+	left := t.build(start, mid)
+	right := t.build(mid, end)
+	t.nodes[index].left, t.nodes[index].right = left, right
+
+	return index
+}
+
+// boxDistance is the distance from q to the nearest point of the box.
+func boxDistance(q Point, lo, hi Point) float64 {
+	dx := math.Max(0, math.Max(lo.X-q.X, q.X-hi.X))
+	dy := math.Max(0, math.Max(lo.Y-q.Y, q.Y-hi.Y))
+	return math.Hypot(dx, dy)
+}
+
+// nearest finds the nearest edge of every color in a single traversal, CLEAR
+// matches every edge.
+func (t *edgeTree) nearest(q Point, colors []EdgeColor) []nearestEdge {
+	found := make([]nearestEdge, len(colors))
+	if len(t.nodes) == 0 {
+		return found
+	}
+
+	// wanted reports whether something in a box this far away with these
+	// colors could replace an edge found so far
+	wanted := func(lower float64, union EdgeColor) bool {
+		for i, c := range colors {
+			if !union.Has(c) {
+				continue
+			}
+			if found[i].curve == nil || lower-boxEpsilon <= math.Abs(found[i].distance.distance) {
+				return true
+			}
+		}
+		return false
+	}
+
+	stack := []int{0}
+	for len(stack) > 0 {
+		n := t.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+
+		if !wanted(boxDistance(q, n.min, n.max), n.colors) {
+			continue
+		}
+
+		if !n.leaf {
+			// visit the nearer child first, it shrinks the search radius
+			l, r := t.nodes[n.left], t.nodes[n.right]
+			if boxDistance(q, l.min, l.max) <= boxDistance(q, r.min, r.max) {
+				stack = append(stack, n.right, n.left)
+			} else {
+				stack = append(stack, n.left, n.right)
+			}
+			continue
+		}
+
+		for _, it := range t.items[n.start:n.end] {
+			if !wanted(boxDistance(q, it.min, it.max), it.edge.Color) {
+				continue
+			}
+
+			sd, st := getSignedDistance(it.edge.Curve, q)
+			for i, c := range colors {
+				if !it.edge.Color.Has(c) {
+					continue
+				}
+
+				f := &found[i]
+				if f.curve == nil || sd.less(f.distance) || (!f.distance.less(sd) && it.order < f.order) {
+					*f = nearestEdge{curve: it.edge.Curve, distance: sd, t: st, order: it.order}
+				}
+			}
+		}
+	}
+
+	return found
+}
+
+// winding returns the winding number of the outline around q by adding up
+// the crossings of the ray going from q to the right.
+func (t *edgeTree) winding(q Point) int {
+	w := 0
+	if len(t.nodes) == 0 {
+		return w
+	}
+
+	stack := []int{0}
+	for len(stack) > 0 {
+		n := t.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+
+		// points on the ray count as above it, so a box that does not reach
+		// below it has no crossings
+		if n.min.Y >= q.Y || n.max.Y < q.Y || n.max.X <= q.X {
+			continue
+		}
+
+		if !n.leaf {
+			stack = append(stack, n.left, n.right)
+			continue
+		}
+
+		for _, it := range t.items[n.start:n.end] {
+			xs, dys := it.edge.Curve.ScanlineIntersections(q.Y)
+			for i, x := range xs {
+				if x > q.X {
+					w += dys[i]
+				}
+			}
+		}
+	}
+
+	return w
+}
@@ -3,8 +3,6 @@ package msdf
 import (
 	"fmt"
 	"math"
-
-	"golang.org/x/image/math/fixed"
 )
 
 type Vector struct {
@@ -32,13 +30,6 @@ func (v *Vector) fromP(b Point) *Vector {
 	return v
 }
 
-func (v *Vector) fromP26_6(a, b fixed.Point26_6) *Vector {
-	xa, ya := unpack_p26_6(a)
-	xb, yb := unpack_p26_6(b)
-	v.fromXY(xa, ya, xb, yb)
-	return v
-}
-
 func (v *Vector) Dot(b *Vector) float64 {
 	return v.X*b.X + v.Y*b.Y
 }
@@ -67,13 +58,6 @@ func (v *Vector) Normalize() *Vector {
 
 }
 
-func (v *Vector) Fixed() fixed.Point26_6 {
-	return fixed.Point26_6{
-		X: pack_i26_6(v.X),
-		Y: pack_i26_6(v.Y),
-	}
-}
-
 func (v *Vector) String() string {
 	return fmt.Sprintf("vec[(%.3f, %.3f) d= %.3f] ", v.X, v.Y, v.Distance())
 }
@@ -0,0 +1,91 @@
+package msdf
+
+import (
+	"sort"
+)
+
+type FillRule int
+
+const (
+	// FillRuleNonZero fills points the outline winds around at least once.
+	FillRuleNonZero FillRule = iota
+	// FillRuleEvenOdd fills points the outline winds around an odd number of times.
+	FillRuleEvenOdd
+	// FillRuleOff keeps the sign given by the nearest edge.
+	FillRuleOff
+)
+
+func (f FillRule) inside(winding int) bool {
+	if f == FillRuleEvenOdd {
+		return winding%2 != 0
+	}
+	return winding != 0
+}
+
+// scanlineIntersections turns the roots of y(t) = y into crossings. Points
+// lying on the line count as above it, so an outline passing through the line
+// at a vertex is counted once across the two edges sharing the vertex.
+func scanlineIntersections(c CurveSampler, y float64, roots []float64) ([]float64, []int) {
+	ts := []float64{0}
+	for _, t := range roots {
+		if t > 0 && t < 1 {
+			ts = append(ts, t)
+		}
+	}
+	sort.Float64s(ts)
+	ts = append(ts, 1)
+
+	above := func(t float64) bool {
+		return c.PointAt(t).Y >= y
+	}
+
+	// the side of the line at t = 0, between the roots and at t = 1, the
+	// curve crosses the line at ts[i] between states i and i+1
+	states := []bool{above(0)}
+	for i := 1; i < len(ts); i++ {
+		states = append(states, above((ts[i-1]+ts[i])/2))
+	}
+	states = append(states, above(1))
+
+	var xs []float64
+	var dys []int
+	for i := range len(states) - 1 {
+		if states[i] == states[i+1] {
+			continue
+		}
+
+		dy := -1
+		if states[i+1] {
+			dy = 1
+		}
+		xs = append(xs, c.PointAt(ts[i]).X)
+		dys = append(dys, dy)
+	}
+
+	return xs, dys
+}
+
+// correctSign flips distances that put q on the wrong side of the outline,
+// the RGB channels of multi-channel fields are judged by their median.
+func correctSign(distances []float64, inside bool) {
+	wrong := func(d float64) bool {
+		return d != 0 && (d > 0) != inside
+	}
+
+	if len(distances) < 3 {
+		if wrong(distances[0]) {
+			distances[0] = -distances[0]
+		}
+		return
+	}
+
+	if wrong(medianf(distances[0], distances[1], distances[2])) {
+		for i := range 3 {
+			distances[i] = -distances[i]
+		}
+	}
+
+	if len(distances) == 4 && wrong(distances[3]) {
+		distances[3] = -distances[3]
+	}
+}